@@ -0,0 +1,157 @@
+package connect4
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Connect Four on bitboards, every column takes 7 bits (6 rows and an empty
+// sentinel bit on top), so the shifted patterns don't wrap to the next column
+
+const (
+	Width  = 7
+	Height = 6
+	// Bits per column, including the sentinel
+	stride = Height + 1
+)
+
+// Column of the move (0 - the leftmost one)
+type Move uint8
+
+// 1-based column number, the same as in the move sequences (see FromMoves)
+func (m Move) String() string {
+	return fmt.Sprint(int(m) + 1)
+}
+
+type Board struct {
+	stones  [2]uint64 // stones of each player, bit 'column*stride + row' (row 0 is the bottom one)
+	heights [Width]uint8
+	history []Move
+}
+
+func NewBoard() *Board {
+	return &Board{history: make([]Move, 0, Width*Height)}
+}
+
+// Create the board by playing the sequence of 1-based column numbers, for example "4453"
+func FromMoves(moves string) (*Board, error) {
+	board := NewBoard()
+	for i, c := range moves {
+		if c < '1' || c >= '1'+Width {
+			return nil, fmt.Errorf("connect4: invalid column %q at %d, expected 1-%d", c, i, Width)
+		}
+		move := Move(c - '1')
+		if board.IsTerminated() {
+			return nil, fmt.Errorf("connect4: move %v at %d, after the game has ended", move, i)
+		}
+		if !board.CanPlay(move) {
+			return nil, fmt.Errorf("connect4: column %v is full at %d", move, i)
+		}
+		board.Play(move)
+	}
+	return board, nil
+}
+
+// Index of the player to move, 0 - the first player
+func (b *Board) Turn() int {
+	return len(b.history) & 1
+}
+
+// Number of the moves played
+func (b *Board) Ply() int {
+	return len(b.history)
+}
+
+func (b *Board) CanPlay(move Move) bool {
+	return move < Width && b.heights[move] < Height
+}
+
+// Drop the stone of the player to move in given column, the move should be legal
+func (b *Board) Play(move Move) {
+	b.stones[b.Turn()] |= 1 << (uint(move)*stride + uint(b.heights[move]))
+	b.heights[move]++
+	b.history = append(b.history, move)
+}
+
+// Undo the last move
+func (b *Board) Undo() {
+	move := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+	b.heights[move]--
+	b.stones[b.Turn()] &^= 1 << (uint(move)*stride + uint(b.heights[move]))
+}
+
+// Legal moves, in the column order
+func (b *Board) Moves() []Move {
+	moves := make([]Move, 0, Width)
+	for move := range Move(Width) {
+		if b.CanPlay(move) {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// Whether there are four connected stones on the bitboard
+func hasFour(stones uint64) bool {
+	// vertical, horizontal, and both diagonals
+	for _, shift := range [4]uint{1, stride, stride - 1, stride + 1} {
+		pairs := stones & (stones >> shift)
+		if pairs&(pairs>>(2*shift)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Index of the player who won, -1 if nobody has (only the last move can win the game)
+func (b *Board) Winner() int {
+	if len(b.history) == 0 {
+		return -1
+	}
+	if last := 1 - b.Turn(); hasFour(b.stones[last]) {
+		return last
+	}
+	return -1
+}
+
+// Whether the game has ended, either with a win or a draw (the board is full)
+func (b *Board) IsTerminated() bool {
+	return b.Winner() != -1 || len(b.history) == Width*Height
+}
+
+// Moves played so far, as the sequence of 1-based columns (see FromMoves)
+func (b *Board) Notation() string {
+	builder := strings.Builder{}
+	for _, move := range b.history {
+		builder.WriteString(move.String())
+	}
+	return builder.String()
+}
+
+// Copy of the board, not sharing any memory with this one
+func (b *Board) Clone() Board {
+	clone := *b
+	clone.history = append(make([]Move, 0, Width*Height), b.history...)
+	return clone
+}
+
+// Board as a grid, the first player's stones are 'x', the second's 'o'
+func (b *Board) String() string {
+	builder := strings.Builder{}
+	for row := Height - 1; row >= 0; row-- {
+		for col := range Width {
+			bit := uint64(1) << (col*stride + row)
+			switch {
+			case b.stones[0]&bit != 0:
+				builder.WriteByte('x')
+			case b.stones[1]&bit != 0:
+				builder.WriteByte('o')
+			default:
+				builder.WriteByte('.')
+			}
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
@@ -0,0 +1,98 @@
+package connect4
+
+import "testing"
+
+func TestFromMoves(t *testing.T) {
+	tests := []struct {
+		moves  string
+		valid  bool
+		winner int
+	}{
+		{"", true, -1},
+		{"4453", true, -1},
+		{"1212121", true, 0},    // vertical
+		{"1122334", true, 0},    // horizontal
+		{"1223343445", true, 1}, // horizontal, second player
+		{"1111111", false, -1},  // full column
+		{"12121218", false, -1}, // invalid column
+		{"12121211", false, -1}, // move after the win
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.moves, func(t *testing.T) {
+			board, err := FromMoves(tt.moves)
+			if (err == nil) != tt.valid {
+				t.Fatalf("err=%v, valid=%v", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if winner := board.Winner(); winner != tt.winner {
+				t.Errorf("Winner=%d, want=%d\n%v", winner, tt.winner, board)
+			}
+			if board.Notation() != tt.moves {
+				t.Errorf("Notation=%s, want=%s", board.Notation(), tt.moves)
+			}
+		})
+	}
+}
+
+func TestPlayUndo(t *testing.T) {
+	board, err := FromMoves("44444455")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.CanPlay(3) || !board.CanPlay(4) || len(board.Moves()) != Width-1 {
+		t.Errorf("Column 4 should be full, moves=%v", board.Moves())
+	}
+
+	before := board.Clone()
+	board.Play(0)
+	board.Undo()
+	if board.stones != before.stones || board.heights != before.heights || board.Notation() != before.Notation() {
+		t.Errorf("Undo didn't restore the board:\n%v\nwant:\n%v", board, &before)
+	}
+}
+
+func TestHasFour(t *testing.T) {
+	// Bits of the cells (column, row)
+	cells := func(points ...[2]int) uint64 {
+		var stones uint64
+		for _, p := range points {
+			stones |= 1 << (p[0]*stride + p[1])
+		}
+		return stones
+	}
+
+	tests := []struct {
+		name   string
+		stones uint64
+		want   bool
+	}{
+		{"vertical", cells([2]int{6, 2}, [2]int{6, 3}, [2]int{6, 4}, [2]int{6, 5}), true},
+		{"horizontal", cells([2]int{3, 5}, [2]int{4, 5}, [2]int{5, 5}, [2]int{6, 5}), true},
+		{"diagonal", cells([2]int{0, 0}, [2]int{1, 1}, [2]int{2, 2}, [2]int{3, 3}), true},
+		{"anti-diagonal", cells([2]int{3, 5}, [2]int{4, 4}, [2]int{5, 3}, [2]int{6, 2}), true},
+		{"three", cells([2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{4, 0}), false},
+		{"vertical wrap", cells([2]int{0, 4}, [2]int{0, 5}, [2]int{1, 0}, [2]int{1, 1}), false},
+		{"diagonal wrap", cells([2]int{0, 3}, [2]int{1, 4}, [2]int{2, 5}, [2]int{4, 0}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasFour(tt.stones); got != tt.want {
+				t.Errorf("hasFour=%v, want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDraw(t *testing.T) {
+	board, err := FromMoves("434237644576543313354222261665167571521717")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !board.IsTerminated() || board.Winner() != -1 || len(board.Moves()) != 0 {
+		t.Errorf("Expected a draw, winner=%d, terminated=%v\n%v", board.Winner(), board.IsTerminated(), board)
+	}
+}
@@ -0,0 +1,120 @@
+package connect4
+
+import (
+	"math/rand"
+	"time"
+	"uttt/_pkg/mcts"
+)
+
+// Connect Four implementation of the mcts.GameOperations, with the uniform random rollouts
+
+type Operations struct {
+	board        Board
+	random       *rand.Rand
+	rolloutMoves []Move // moves played in the last rollout, used by the RAVE
+}
+
+func NewOperations(board Board) *Operations {
+	return &Operations{
+		board:  board,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Create the search tree of given position, with the solver enabled
+func NewTree(board Board) (*mcts.MCTS[Move], *Operations) {
+	ops := NewOperations(board)
+	tree := mcts.NewMTCS(mcts.UCB1[Move], ops, mcts.TerminalFlag(board.IsTerminated()))
+	tree.SetSolver(true)
+	return tree, ops
+}
+
+// Current position of the operations
+func (ops *Operations) Board() *Board {
+	return &ops.board
+}
+
+func (ops *Operations) ExpandNode(node *mcts.NodeBase[Move]) uint32 {
+	return ops.ExpandNodeIn(node, func(n int) []mcts.NodeBase[Move] {
+		return make([]mcts.NodeBase[Move], n)
+	})
+}
+
+// Expand the node, allocating the children with 'alloc' (see mcts.ArenaExpander),
+// the terminal positions have no children
+func (ops *Operations) ExpandNodeIn(node *mcts.NodeBase[Move], alloc func(int) []mcts.NodeBase[Move]) uint32 {
+	if ops.board.IsTerminated() {
+		return 0
+	}
+
+	moves := ops.board.Moves()
+	node.Children = alloc(len(moves))
+	for i, move := range moves {
+		ops.board.Play(move)
+		terminal := ops.board.IsTerminated()
+		ops.board.Undo()
+
+		node.Children[i] = *mcts.NewBaseNode(node, move, terminal)
+	}
+	return uint32(len(moves))
+}
+
+func (ops *Operations) Traverse(move Move) {
+	ops.board.Play(move)
+}
+
+func (ops *Operations) BackTraverse() {
+	ops.board.Undo()
+}
+
+// Play random moves until the game ends, returns the result from the
+// perspective of the side to move
+func (ops *Operations) Rollout() mcts.Result {
+	leafTurn := ops.board.Turn()
+	ops.rolloutMoves = ops.rolloutMoves[:0]
+
+	var moves [Width]Move
+	for !ops.board.IsTerminated() {
+		n := 0
+		for move := range Move(Width) {
+			if ops.board.CanPlay(move) {
+				moves[n] = move
+				n++
+			}
+		}
+		move := moves[ops.random.Intn(n)]
+		ops.board.Play(move)
+		ops.rolloutMoves = append(ops.rolloutMoves, move)
+	}
+
+	var result mcts.Result = 0.5
+	if winner := ops.board.Winner(); winner == leafTurn {
+		result = 1
+	} else if winner != -1 {
+		result = 0
+	}
+
+	for range ops.rolloutMoves {
+		ops.board.Undo()
+	}
+	return result
+}
+
+// Moves played in the last rollout (see mcts.RolloutRecorder)
+func (ops *Operations) RolloutMoves() []Move {
+	return ops.rolloutMoves
+}
+
+// Reseed the rollouts' random number generator (see mcts.Seeder)
+func (ops *Operations) Seed(seed int64) {
+	ops.random.Seed(seed)
+}
+
+func (ops *Operations) Reset() {}
+
+func (ops *Operations) Clone() mcts.GameOperations[Move] {
+	return &Operations{
+		board:  ops.board.Clone(),
+		random: rand.New(rand.NewSource(ops.random.Int63())),
+	}
+}
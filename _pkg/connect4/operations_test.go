@@ -0,0 +1,81 @@
+package connect4
+
+import (
+	"testing"
+	"uttt/_pkg/mcts"
+	"uttt/_pkg/mcts/mctstest"
+)
+
+// Exact result for the side to move (1 - win, 0 - draw, -1 - loss), use only near the end of the game
+func negamax(b *Board) int {
+	if b.Winner() != -1 {
+		return -1
+	}
+	if b.Ply() == Width*Height {
+		return 0
+	}
+	best := -1
+	for _, move := range b.Moves() {
+		b.Play(move)
+		best = max(best, -negamax(b))
+		b.Undo()
+	}
+	return best
+}
+
+func position(t *testing.T, moves string) func() mcts.GameOperations[Move] {
+	return func() mcts.GameOperations[Move] {
+		board, err := FromMoves(moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewOperations(*board)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	// Late positions of the drawn game, the results are checked with the negamax
+	const game = "434237644576543313354222261665167571521717"
+	endgames := []struct {
+		ply    int
+		result mcts.Result
+	}{{30, 0.5}, {37, 0}}
+	for _, e := range endgames {
+		board, _ := FromMoves(game[:e.ply])
+		if value := negamax(board); mcts.Result(value+1)/2 != e.result {
+			t.Fatalf("Negamax of %s=%d, want result=%v", game[:e.ply], value, e.result)
+		}
+	}
+
+	mctstest.Run(t, mctstest.Game[Move]{
+		New:      func() mcts.GameOperations[Move] { return NewOperations(*NewBoard()) },
+		MaxPlies: Width * Height,
+		Solved: []mctstest.SolvedPosition[Move]{
+			{Name: "win in 1", New: position(t, "121212"), Result: 1, BestMoves: []Move{0}},
+			{Name: "loss in 2", New: position(t, "27374"), Result: 0},
+			{Name: "win in 3", New: position(t, "2737"), Result: 1, BestMoves: []Move{3}},
+			{Name: "draw", New: position(t, game[:30]), Result: 0.5},
+			{Name: "endgame loss", New: position(t, game[:37]), Result: 0},
+		},
+	})
+}
+
+func TestTreeSearch(t *testing.T) {
+	// Search shouldn't depend on UTTT, with the threads, RAVE and the arena
+	board, _ := FromMoves("2737")
+	tree, ops := NewTree(*board)
+	tree.SetRave(true)
+	tree.SetArena(mcts.NewNodeArena[Move](mcts.DefaultArenaChunkSize))
+	tree.Reset(ops, false)
+	tree.SetSelectionPolicy(mcts.UCB1RAVE[Move])
+	tree.SetLimits(mcts.DefaultLimits().SetThreads(4).SetCycles(50000))
+	tree.SearchMultiThreaded(ops)
+	tree.Synchronize()
+
+	if best := tree.RootSignature(); best != 3 {
+		t.Errorf("Best move=%v, want=4 (the double threat)", best)
+	}
+	if tree.Size() != uint32(tree.Count()) {
+		t.Errorf("Size=%d, counted %d nodes", tree.Size(), tree.Count())
+	}
+}
@@ -2,56 +2,121 @@ package uttt
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"uttt/_pkg/mcts"
 )
 
 type Cli struct {
 	engine *Engine
+	in     io.Reader
+	out    io.Writer
+	outMu  sync.Mutex // the info lines are written by the search goroutine
+
+	// Running search, started with the 'go' command
+	cancel context.CancelFunc // stops the search
+	search chan struct{}      // closed after the best move is printed, nil if there is no search
+
+	// How often the info lines are printed during the search
+	infoInterval time.Duration
 }
 
-// Get new cli object (pointer)
+const (
+	EngineName   = "bttt"
+	EngineAuthor = "IlikeChooros"
+
+	DefaultInfoInterval = 500 * time.Millisecond
+)
+
+// Get new cli object (pointer), reading the commands from the stdin
 func NewCli() *Cli {
-	cli := new(Cli)
-	cli.engine = NewEngine()
-	return cli
+	return NewCliIO(os.Stdin, os.Stdout)
 }
 
-// Start the cli loop
+// Get new cli object, reading the commands from 'in', and writing the responses to 'out'
+func NewCliIO(in io.Reader, out io.Writer) *Cli {
+	return &Cli{
+		engine:       NewEngine(),
+		in:           in,
+		out:          out,
+		infoInterval: DefaultInfoInterval,
+	}
+}
+
+// Start the cli loop, until the 'quit' command or the end of the input,
+// see docs/protocol.md
 func (cli *Cli) Start() {
-	defer fmt.Println("Exiting...")
+	defer cli.println("Exiting...")
+	defer cli.stopSearch()
 
-	exit_flags := [4]string{
+	exit_flags := []string{
 		"e", "q", "exit", "quit",
 	}
 
 	// Initialize the lib
 	Init()
 
-	var arg string
-	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Println("Ultimate Tic Tac Toe engine")
-	for {
-		scanner.Scan()
-		arg = scanner.Text()
+	scanner := bufio.NewScanner(cli.in)
+	cli.println("Ultimate Tic Tac Toe engine")
+	for scanner.Scan() {
+		arg := strings.TrimSpace(scanner.Text())
+		if arg == "" {
+			continue
+		}
 
 		// Check if that's an exit flag
-		for _, v := range exit_flags {
-			if v == arg {
-				return
-			}
+		if slices.Contains(exit_flags, arg) {
+			return
 		}
 
-		// Parse the command asynchronously
-		go func() {
-			if err := cli.parseArgument(arg); err != nil {
-				fmt.Println(err)
-			}
-		}()
+		// Commands are handled in order, only the search runs in the background
+		if err := cli.parseArgument(arg); err != nil {
+			cli.println(err)
+		}
+	}
+}
+
+// Write a line to the output, safe to call during the search
+func (cli *Cli) println(args ...any) {
+	cli.outMu.Lock()
+	defer cli.outMu.Unlock()
+	fmt.Fprintln(cli.out, args...)
+}
+
+func (cli *Cli) printf(format string, args ...any) {
+	cli.outMu.Lock()
+	defer cli.outMu.Unlock()
+	fmt.Fprintf(cli.out, format, args...)
+}
+
+// Whether the search started with 'go' is still running
+func (cli *Cli) searching() bool {
+	if cli.search == nil {
+		return false
+	}
+	select {
+	case <-cli.search:
+		cli.search = nil
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop the running search, waits until its best move is printed
+func (cli *Cli) stopSearch() {
+	if cli.search != nil {
+		cli.cancel()
+		<-cli.search
+		cli.search = nil
 	}
 }
 
@@ -73,23 +138,37 @@ func _parseIntToken(tokenIdx int, tokens []string, f func(int)) error {
 	return nil
 }
 
+// Commands handled while the search is running, the others would change the searched position
+var _cliSearchCommands = []string{"stop", "isready", "uttti"}
+
 // Handle given input arguments as 1 string (should be seprated by space)
 func (cli *Cli) parseArgument(arg string) error {
-	if len(arg) == 0 {
+	// split the arguments into tokens
+	tokens := strings.Fields(arg)
+	if len(tokens) == 0 {
 		return fmt.Errorf("[CLI] Expected arguments for parsing")
 	}
 
-	// split the arguments into tokens
-	tokens := strings.Split(arg, " ")
+	if cli.searching() && !slices.Contains(_cliSearchCommands, tokens[0]) {
+		return fmt.Errorf("[CLI] Command %s isn't allowed during the search, send 'stop' first", tokens[0])
+	}
 
 	switch tokens[0] {
+	case "uttti":
+		cli.handleUttti()
+	case "isready":
+		cli.println("readyok")
+	case "ucinewgame":
+		cli.engine.SetPosition(*NewPosition())
+	case "setoption":
+		return cli.handleSetOption(tokens[1:])
+	case "options":
+		for _, option := range cli.engine.Options().List() {
+			cli.printf("%v value %s\n", option, option.Value())
+		}
 	case "stop":
-		cli.engine.Stop()
+		cli.stopSearch()
 	case "go":
-		// there should be at least 2 tokens
-		if len(tokens) < 2 {
-			return fmt.Errorf(_cliErrorFormat, 2, "go")
-		}
 		return cli.handleGo(tokens[1:])
 	case "position":
 		if len(tokens) < 2 {
@@ -98,23 +177,40 @@ func (cli *Cli) parseArgument(arg string) error {
 		return cli.handlePosition(tokens[1:])
 
 	case "getpos":
-		fmt.Println(cli.engine.Position().Notation())
+		cli.println(cli.engine.Position().Notation())
 	case "makemove":
 		if len(tokens) < 2 {
 			return fmt.Errorf(_cliErrorFormat, 1, "makemove")
 		}
-		for _, token := range tokens[1:] {
-			mv := MoveFromString(token)
-			if mv == PosIllegal {
-				return fmt.Errorf("[CLI] invalid move notation, expected [A-C][1-3][a-c][1-3]")
-			}
-			if !cli.engine.Position().IsLegal(mv) {
-				return fmt.Errorf("[CLI] Illegal move: %s", token)
+		// Check all of the moves first, so none is played if any of them is invalid
+		pos := cli.engine.Position().Clone()
+		moves, err := _playMoves(&pos, tokens[1:], cli.engine.MoveFormat())
+		if err != nil {
+			return err
+		}
+		for _, move := range moves {
+			// Keeps the search tree of this move
+			if err := cli.engine.MakeMove(move); err != nil {
+				return fmt.Errorf("[CLI] %w", err)
 			}
-			cli.engine.Position().MakeMove(mv)
 		}
 	case "undomove":
-		cli.engine.Position().UndoMove()
+		cli.engine.UndoMove()
+	case "tree":
+		if len(tokens) < 3 {
+			return fmt.Errorf(_cliErrorFormat, 2, "tree")
+		}
+		return cli.handleTree(tokens[1:])
+	case "savetree":
+		if len(tokens) < 2 {
+			return fmt.Errorf(_cliErrorFormat, 1, "savetree")
+		}
+		return cli.handleSaveTree(tokens[1])
+	case "loadtree":
+		if len(tokens) < 2 {
+			return fmt.Errorf(_cliErrorFormat, 1, "loadtree")
+		}
+		return cli.handleLoadTree(tokens[1])
 	case "test":
 		if len(tokens) < 2 {
 			return fmt.Errorf(_cliErrorFormat, 1, "test")
@@ -125,6 +221,59 @@ func (cli *Cli) parseArgument(arg string) error {
 	return nil
 }
 
+// Print the search tree, up to given depth, optionally skipping the nodes with less visits:
+// tree dot|json <depth> [minvisits]
+func (cli *Cli) handleTree(tokens []string) error {
+	opts := mcts.ExportOptions{}
+	if err := _parseIntToken(1, tokens, func(n int) { opts.MaxDepth = n }); err != nil {
+		return err
+	}
+	if len(tokens) > 2 {
+		if err := _parseIntToken(2, tokens, func(n int) { opts.MinVisits = int32(n) }); err != nil {
+			return err
+		}
+	}
+
+	root := cli.engine.Mcts().Root
+	switch tokens[0] {
+	case "dot":
+		return mcts.WriteDot(cli.out, root, opts)
+	case "json":
+		return mcts.WriteJSON(cli.out, root, opts)
+	}
+	return fmt.Errorf("[CLI] Unknown tree format %s, expected dot or json", tokens[0])
+}
+
+// Save the search tree to given file: savetree <file>
+func (cli *Cli) handleSaveTree(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("[CLI] Couldn't create the file: %w", err)
+	}
+	defer file.Close()
+
+	if err := cli.engine.SaveTree(file); err != nil {
+		return fmt.Errorf("[CLI] Couldn't save the tree: %w", err)
+	}
+	cli.printf("Saved %d nodes to %s\n", cli.engine.Mcts().Size(), path)
+	return nil
+}
+
+// Load the search tree from given file, and set its root position: loadtree <file>
+func (cli *Cli) handleLoadTree(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("[CLI] Couldn't open the file: %w", err)
+	}
+	defer file.Close()
+
+	if err := cli.engine.LoadTree(file); err != nil {
+		return fmt.Errorf("[CLI] Couldn't load the tree: %w", err)
+	}
+	cli.printf("Loaded %d nodes, position %s\n", cli.engine.Mcts().Size(), cli.engine.Position().Notation())
+	return nil
+}
+
 // func hashTest(depth int, pos *Position, tt *HashTable[HashEntryBase]) (uint, uint) {
 
 // 	if depth == 0 {
@@ -167,7 +316,7 @@ func (cli *Cli) handleTest(tokens []string) error {
 			nodes := uint64(0)
 
 			defer func() {
-				fmt.Printf("\rAvg %.1f Mnps\033[K\n", float64(nodes)/avgtime)
+				cli.printf("\rAvg %.1f Mnps\033[K\n", float64(nodes)/avgtime)
 			}()
 
 			const Ntries = 10
@@ -175,7 +324,7 @@ func (cli *Cli) handleTest(tokens []string) error {
 			for i := 0; i < Ntries; i++ {
 				nodes = Perft(cli.engine.Position(), depth, true, false)
 				avgtime += float64(time.Since(now).Microseconds()-int64(avgtime)) / float64(i+1)
-				fmt.Printf("\rProgress: %.1f (eta: %s)\033[K",
+				cli.printf("\rProgress: %.1f (eta: %s)\033[K",
 					(float32(i+1)/Ntries)*100,
 					time.Duration(avgtime*float64(Ntries-(i+1))*1000).String())
 				now = time.Now()
@@ -183,6 +332,42 @@ func (cli *Cli) handleTest(tokens []string) error {
 		})
 	}
 
+	// Compare the rollout policies: test rollout <games>
+	if tokens[0] == "rollout" {
+		var games int
+		if err := _parseIntToken(1, tokens, func(n int) { games = n }); err != nil {
+			return err
+		}
+		if games < 1 {
+			return fmt.Errorf("[CLI] expected at least 1 game, got %d", games)
+		}
+		cli.testRolloutPolicies(games)
+		return nil
+	}
+
+	// Compare the root policies at equal cycles: test halving <games> <cycles>
+	if tokens[0] == "halving" {
+		if len(tokens) < 3 {
+			return fmt.Errorf(_cliErrorFormat, 2, "test halving")
+		}
+		games, err := strconv.Atoi(tokens[1])
+		if err != nil {
+			return err
+		}
+		if games < 1 {
+			return fmt.Errorf("[CLI] expected at least 1 game, got %d", games)
+		}
+		cycles, err := strconv.Atoi(tokens[2])
+		if err != nil {
+			return err
+		}
+		if cycles < 1 {
+			return fmt.Errorf("[CLI] expected at least 1 cycle, got %d", cycles)
+		}
+		cli.testRootPolicies(games, uint32(cycles))
+		return nil
+	}
+
 	// Test hasing values, by performing perft test up to certain depth, and see how many collisions we get
 	// if tokens[0] == "hash" {
 	// 	return _parseIntToken(1, tokens, func(i int) {
@@ -197,26 +382,60 @@ func (cli *Cli) handleTest(tokens []string) error {
 	return nil
 }
 
+// Measure the rollouts per second of each policy (from the current position),
+// and play 'games' games between every pair of the policies
+func (cli *Cli) testRolloutPolicies(games int) {
+	policies := make([]RolloutPolicy, len(RolloutPolicyNames))
+	for i, name := range RolloutPolicyNames {
+		policies[i], _ = NewRolloutPolicy(name)
+	}
+
+	ops := newUtttOps(cli.engine.Position().Clone())
+	for i, policy := range policies {
+		ops.policy = policy
+		start := time.Now()
+		for range games {
+			ops.Rollout()
+		}
+		elapsed := time.Since(start).Seconds()
+		cli.printf("%-12s %.0f rollouts/s\n", RolloutPolicyNames[i], float64(games)/elapsed)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := range policies {
+		for j := i + 1; j < len(policies); j++ {
+			wins, draws, losses := PlayRolloutMatch(cli.engine.Position(), policies[i], policies[j], games, random)
+			cli.printf("%s vs %s: +%d =%d -%d (%.1f%%)\n",
+				RolloutPolicyNames[i], RolloutPolicyNames[j], wins, draws, losses,
+				100*(float64(wins)+0.5*float64(draws))/float64(games))
+		}
+	}
+}
+
 // Handle the 'go' command
 // Possible tokens:
-// go perft|[ depth <n> | nodes <n> | movetime <n>]
+// go perft|[ depth <n> | nodes <n> | cycles <n> | movetime <n> | threads <n> | mbsize <n> | rootparallel |
+// halving | wtime <ms> | btime <ms> | winc <ms> | binc <ms> | movestogo <n> | seed <n> | infinite ]
+// without the limits the search runs until 'stop'
 func (cli *Cli) handleGo(tokens []string) error {
 
 	// Handle 'perft' command separately
-	switch tokens[0] {
-	case "perft":
-		// Next token should be depth
-		return _parseIntToken(1, tokens, func(depth int) {
-			Perft(cli.engine.Position(), depth, false, true)
-		})
-	case "valid-perft":
-		return _parseIntToken(1, tokens, func(depth int) {
-			Perft(cli.engine.Position(), depth, true, true)
-		})
+	if len(tokens) > 0 {
+		switch tokens[0] {
+		case "perft":
+			// Next token should be depth
+			return _parseIntToken(1, tokens, func(depth int) {
+				Perft(cli.engine.Position(), depth, false, true)
+			})
+		case "valid-perft":
+			return _parseIntToken(1, tokens, func(depth int) {
+				Perft(cli.engine.Position(), depth, true, true)
+			})
+		}
 	}
 
-	// Parse the search commands
-	limits := mcts.DefaultLimits()
+	// Parse the search commands, starting with the engine options
+	limits := cli.engine.NewLimits()
 	var err error
 	for i := 0; i < len(tokens); i++ {
 
@@ -225,12 +444,40 @@ func (cli *Cli) handleGo(tokens []string) error {
 		}
 
 		switch tokens[i] {
-		// Move these into 'engine options'
+		// Override the engine options for this search
 		case "threads":
 			err = _parseIntToken(i+1, tokens, func(threads int) {
 				limits.SetThreads(threads)
 				i++
 			})
+		case "wtime", "btime", "winc", "binc":
+			side := ClockSide(CrossTurn)
+			if tokens[i][0] == 'b' {
+				side = ClockSide(CircleTurn)
+			}
+			inc := strings.HasSuffix(tokens[i], "inc")
+			err = _parseIntToken(i+1, tokens, func(ms int) {
+				if inc {
+					limits.Increment[side] = ms
+				} else {
+					limits.SetClock(side, ms, limits.Increment[side])
+				}
+				i++
+			})
+		case "movestogo":
+			err = _parseIntToken(i+1, tokens, func(n int) {
+				limits.SetMovesToGo(n)
+				i++
+			})
+		case "rootparallel":
+			limits.SetParallel(mcts.RootParallel)
+		case "halving":
+			limits.SetRootPolicy(mcts.RootSequentialHalving)
+		case "seed":
+			err = _parseIntToken(i+1, tokens, func(seed int) {
+				limits.SetSeed(int64(seed))
+				i++
+			})
 		case "mbsize":
 			err = _parseIntToken(i+1, tokens, func(mbsize int) {
 				limits.SetMbSize(mbsize)
@@ -249,6 +496,11 @@ func (cli *Cli) handleGo(tokens []string) error {
 				limits.SetNodes(uint32(nodes))
 				i++
 			})
+		case "cycles":
+			err = _parseIntToken(i+1, tokens, func(cycles int) {
+				limits.SetCycles(uint32(cycles))
+				i++
+			})
 		case "movetime":
 			err = _parseIntToken(i+1, tokens, func(movetime int) {
 				limits.SetMovetime(movetime)
@@ -262,24 +514,119 @@ func (cli *Cli) handleGo(tokens []string) error {
 		}
 	}
 
-	// Run the engine
+	// Only the clock of the side to move is used, without it the search would never stop
+	side := ClockSide(cli.engine.Position().Turn())
+	if err == nil && limits.TimeLeft[side] < 0 && limits.TimeLeft[1-side] >= 0 {
+		names := [2]string{"wtime", "btime"}
+		err = fmt.Errorf("[CLI] %s given without %s of the side to move", names[1-side], names[side])
+	}
+
+	// Run the engine in the background, the search tree is kept between the moves,
+	// use 'position' command to discard it
 	if err == nil {
 		cli.engine.SetLimits(limits)
-		cli.engine.NewGame()
-		results := cli.engine.Think()
-		fmt.Println("info", results)
+		cli.startSearch()
 	}
 
 	return err
 }
 
+// Start the search, printing the info lines every 'infoInterval',
+// and the final ones followed by the best move
+func (cli *Cli) startSearch() {
+	tree := cli.engine.Mcts()
+	turn := cli.engine.Position().Turn()
+	format := cli.engine.MoveFormat()
+	start := time.Now()
+	tree.ResetListener()
+	tree.StatsListener().OnInterval(cli.infoInterval, func(stats mcts.ListenerTreeStats[PosType]) {
+		cli.printInfo(ToSearchResult(stats, turn), stats.TimeMs, format)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	cli.cancel, cli.search = cancel, done
+
+	go func() {
+		defer cancel()
+		result := cli.engine.ThinkContext(ctx)
+		cli.printInfo(result, int(time.Since(start).Milliseconds()), format)
+
+		bestmove := "none"
+		if line, ok := result.MainLine(); ok {
+			bestmove = FormatMove(line.Bestmove, format)
+		}
+
+		// Finish the search before printing the best move, so the next
+		// command sent right after it isn't refused
+		cli.outMu.Lock()
+		defer cli.outMu.Unlock()
+		close(done)
+		fmt.Fprintln(cli.out, "bestmove", bestmove)
+	}()
+}
+
+// Print the info line of every principal variation, with the moves in given format:
+// info multipv <n> depth <d> score value <0-100>|mate <plies> wdl <w> <d> <l> nodes <n> cycles <n> cps <n> time <ms> pv <moves>...
+func (cli *Cli) printInfo(result SearchResult, timeMs int, format MoveFormat) {
+	builder := strings.Builder{}
+	for i, line := range result.Lines {
+		score := "value"
+		if line.ScoreType == MateScore {
+			score = "mate"
+		}
+		fmt.Fprintf(&builder, "info multipv %d depth %d score %s %d wdl %d %d %d nodes %d cycles %d cps %d time %d pv",
+			i+1, result.Depth, score, line.Value,
+			int(1000*line.WDL.Win), int(1000*line.WDL.Draw), int(1000*line.WDL.Loss),
+			result.Nodes, result.Cycles, result.Cps, timeMs)
+		for _, move := range line.Pv {
+			builder.WriteString(" " + FormatMove(move, format))
+		}
+		builder.WriteByte('\n')
+	}
+
+	cli.outMu.Lock()
+	defer cli.outMu.Unlock()
+	io.WriteString(cli.out, builder.String())
+}
+
+// Print the engine's name and options, then 'uttiok'
+func (cli *Cli) handleUttti() {
+	cli.printf("id name %s\n", EngineName)
+	cli.printf("id author %s\n", EngineAuthor)
+	for _, option := range cli.engine.Options().List() {
+		cli.println(option)
+	}
+	cli.println("uttiok")
+}
+
+// Set the engine option (see Engine.Options): setoption name <name> value <value>
+func (cli *Cli) handleSetOption(tokens []string) error {
+	// Both the name and the value may contain spaces
+	i := slices.Index(tokens, "value")
+	if len(tokens) == 0 || tokens[0] != "name" || i < 2 {
+		return fmt.Errorf("[CLI] expected 'setoption name <name> value <value>'")
+	}
+	name, value := strings.Join(tokens[1:i], " "), strings.Join(tokens[i+1:], " ")
+	if err := cli.engine.SetOption(name, value); err != nil {
+		return fmt.Errorf("[CLI] %w", err)
+	}
+	return nil
+}
+
 // Handle position command
 // Possible options:
-// position startpos | <notation>
+// position startpos | <notation> [moves <move>...]
 // notation - is a string representing the bttt position, has 3 segments
 // first one is the board position itself, then side to move,
-// last one 'big index'
+// last one 'big index'. The moves are played on that position, and kept
+// in its history, so they can be undone
 func (cli *Cli) handlePosition(tokens []string) error {
+	var moves []string
+	if i := slices.Index(tokens, "moves"); i != -1 {
+		tokens, moves = tokens[:i], tokens[i+1:]
+	}
+
 	switch len(tokens) {
 	case 1:
 		// Expecting 'startpos'
@@ -296,14 +643,63 @@ func (cli *Cli) handlePosition(tokens []string) error {
 
 	// Return the parsing result of this position
 	pos := NewPosition()
-	err := pos.FromNotation(strings.Join(tokens, " "))
+	if err := pos.FromNotation(strings.Join(tokens, " ")); err != nil {
+		return err
+	}
+	if _, err := _playMoves(pos, moves, cli.engine.MoveFormat()); err != nil {
+		return err
+	}
 
 	// If we don't run into any exceptions, set this position as new one
 	// Simply to preserve the position state, if user has given invalid position
-	if err == nil {
-		cli.engine.mcts.ops.position = *pos
+	cli.engine.SetPosition(*pos)
+	return nil
+}
+
+// Play the moves on the position, stops at the first invalid one, and returns
+// the error naming its ply (1 - the first of the moves). The format of every move is detected,
+// except for the codingame one, where each move is a pair of tokens: <row> <col>
+func _playMoves(pos *Position, tokens []string, format MoveFormat) ([]PosType, error) {
+	if format == FormatCodinGame {
+		if len(tokens)%2 != 0 {
+			return nil, fmt.Errorf("[CLI] Expected the moves as pairs of tokens: <row> <col>")
+		}
+		pairs := make([]string, 0, len(tokens)/2)
+		for i := 0; i < len(tokens); i += 2 {
+			pairs = append(pairs, tokens[i]+" "+tokens[i+1])
+		}
+		tokens = pairs
+	} else {
+		format = FormatAuto
 	}
 
-	// Return the error result
-	return err
+	moves := make([]PosType, 0, len(tokens))
+	for i, token := range tokens {
+		move, err := ParseMove(token, format)
+		if err != nil {
+			return nil, fmt.Errorf("[CLI] Invalid move %q at ply %d, expected %s", token, i+1, format.Syntax())
+		}
+		if pos.IsTerminated() {
+			return nil, fmt.Errorf("[CLI] Move %s at ply %d, after the game has ended", token, i+1)
+		}
+		if !pos.IsLegal(move) {
+			return nil, fmt.Errorf("[CLI] Illegal move %s at ply %d, possible moves=[%s]", token, i+1, pos.GenerateMoves().String())
+		}
+		pos.MakeMove(move)
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// Play 'games' games between the sequential halving and the default root policy,
+// both searching 'cycles' cycles per move, from the current position
+func (cli *Cli) testRootPolicies(games int, cycles uint32) {
+	halving, plain := NewEngine(), NewEngine()
+	halving.SetLimits(mcts.DefaultLimits().SetCycles(cycles).SetRootPolicy(mcts.RootSequentialHalving))
+	plain.SetLimits(mcts.DefaultLimits().SetCycles(cycles))
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	wins, draws, losses := PlaySearchMatch(cli.engine.Position(), halving, plain, games, 2, random)
+	cli.printf("halving vs default (%d cycles): +%d =%d -%d (%.1f%%)\n",
+		cycles, wins, draws, losses, 100*(float64(wins)+0.5*float64(draws))/float64(games))
 }
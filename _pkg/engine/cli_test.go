@@ -0,0 +1,342 @@
+package uttt
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Cli driven through the pipes, the same way a GUI would do it
+type cliSession struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+}
+
+func newCliSession(t *testing.T) *cliSession {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	cli := NewCliIO(inReader, outWriter)
+	cli.infoInterval = 10 * time.Millisecond
+
+	session := &cliSession{t: t, in: inWriter, lines: make(chan string, 1<<10)}
+	go func() {
+		cli.Start()
+		outWriter.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			session.lines <- scanner.Text()
+		}
+		close(session.lines)
+	}()
+
+	// Wait until the cli exits
+	t.Cleanup(func() {
+		inWriter.Close()
+		for range session.lines {
+		}
+	})
+	session.expect("Ultimate Tic Tac Toe engine")
+	return session
+}
+
+func (s *cliSession) send(command string) {
+	fmt.Fprintln(s.in, command)
+}
+
+// Read the output until the line starting with 'prefix', returns the lines read (including that one)
+func (s *cliSession) expect(prefix string) []string {
+	s.t.Helper()
+	var lines []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("Output closed while waiting for %q, got %q", prefix, lines)
+			}
+			lines = append(lines, line)
+			if strings.HasPrefix(line, prefix) {
+				return lines
+			}
+		case <-timeout:
+			s.t.Fatalf("Timed out waiting for %q, got %q", prefix, lines)
+		}
+	}
+}
+
+func TestCliHandshake(t *testing.T) {
+	session := newCliSession(t)
+	session.send("uttti")
+	lines := session.expect("uttiok")
+
+	for _, want := range []string{"id name " + EngineName, "id author " + EngineAuthor, "option name Threads type spin default 1 min 1 max 256"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("Missing %q in the handshake %q", want, lines)
+		}
+	}
+
+	session.send("isready")
+	if lines := session.expect("readyok"); len(lines) != 1 {
+		t.Errorf("Expected only readyok, got %q", lines)
+	}
+}
+
+func TestCliSearch(t *testing.T) {
+	session := newCliSession(t)
+	session.send("setoption name Threads value 2")
+	session.send("setoption name MultiPV value 2")
+	session.send("ucinewgame")
+	session.send("position startpos")
+	session.send("go cycles 2000")
+	lines := session.expect("bestmove")
+
+	var infos []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "info ") {
+			infos = append(infos, line)
+		} else if !strings.HasPrefix(line, "bestmove ") {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+	if len(infos) < 2 || !strings.HasPrefix(infos[len(infos)-2], "info multipv 1 depth ") ||
+		!strings.HasPrefix(infos[len(infos)-1], "info multipv 2 depth ") {
+		t.Fatalf("Expected the final info lines of both lines, got %q", infos)
+	}
+
+	bestmove := MoveFromString(strings.TrimPrefix(lines[len(lines)-1], "bestmove "))
+	if !NewPosition().IsLegal(bestmove) {
+		t.Errorf("Illegal best move %q", lines[len(lines)-1])
+	}
+}
+
+func TestCliMate(t *testing.T) {
+	session := newCliSession(t)
+	session.send("position xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1")
+	session.send("go cycles 1000")
+	lines := session.expect("bestmove")
+
+	info, bestmove := lines[len(lines)-2], lines[len(lines)-1]
+	if !strings.Contains(info, " score mate 1 wdl 1000 0 0 ") {
+		t.Errorf("Expected mate in 1, got %q", info)
+	}
+	if pv := strings.Fields(info); bestmove != "bestmove "+pv[len(pv)-1] {
+		t.Errorf("%q doesn't match the pv %q", bestmove, info)
+	}
+}
+
+func TestCliStop(t *testing.T) {
+	session := newCliSession(t)
+	session.send("go infinite")
+
+	// Periodic info lines
+	for range 2 {
+		session.expect("info multipv 1 depth ")
+	}
+
+	// Only some of the commands are allowed during the search
+	session.send("isready")
+	session.expect("readyok")
+	session.send("position startpos")
+	session.expect("[CLI] Command position isn't allowed during the search")
+
+	session.send("stop")
+	session.expect("bestmove ")
+
+	// Next search is accepted right after the best move
+	session.send("go cycles 100")
+	session.expect("bestmove ")
+	session.send("go cycles 100")
+	for _, line := range session.expect("bestmove ") {
+		if strings.HasPrefix(line, "[CLI]") {
+			t.Errorf("Unexpected error %q", line)
+		}
+	}
+
+	// Next search is possible, the input's end stops it
+	session.send("go")
+	session.expect("info ")
+	session.in.Close()
+	session.expect("bestmove ")
+	session.expect("Exiting...")
+}
+
+func TestCliOneSidedClock(t *testing.T) {
+	session := newCliSession(t)
+	session.send("position startpos moves B2b2")
+
+	// Clock of the side to move stops the search
+	session.send("go btime 100")
+	session.expect("bestmove ")
+
+	// Only the other side's clock, the search wouldn't stop
+	session.send("go wtime 100 winc 10")
+	session.expect("[CLI] wtime given without btime of the side to move")
+
+	// No search was started
+	session.send("position startpos")
+	session.send("isready")
+	for _, line := range session.expect("readyok") {
+		if strings.HasPrefix(line, "[CLI]") || strings.HasPrefix(line, "info ") {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+}
+
+func TestCliSetOption(t *testing.T) {
+	tests := []struct {
+		command string
+		valid   bool
+	}{
+		{"setoption name Threads value 4", true},
+		{"setoption name hash value 16", true},
+		{"setoption name MultiPV value 3", true},
+		{"setoption name exploration value 1.2", true},
+		{"setoption name Threads value 0", false},
+		{"setoption name Threads value x", false},
+		{"setoption name contempt value 2", false},
+		{"setoption name BestChild value winrate", true},
+		{"setoption name Policy value unknown", false},
+		{"setoption name Unknown value 1", false},
+		{"setoption name Threads", false},
+		{"setoption name value 1", false},
+		{"setoption Threads value 1", false},
+		{"setoption name Rollout Policy value uniform", false},
+		{"setoption name Policy value ucb1 tuned", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cli := NewCliIO(strings.NewReader(""), &bytes.Buffer{})
+			if err := cli.parseArgument(tt.command); (err == nil) != tt.valid {
+				t.Errorf("err=%v, valid=%v", err, tt.valid)
+			}
+		})
+	}
+
+	cli := NewCliIO(strings.NewReader(""), &bytes.Buffer{})
+	_ = cli.parseArgument("setoption name Threads value 4")
+	_ = cli.parseArgument("setoption name Exploration value 1.2")
+	if threads := cli.engine.NewLimits().NThreads; threads != 4 || cli.engine.SearchConfig().Exploration != 1.2 {
+		t.Errorf("threads=%d exploration=%v, want=4, 1.2", threads, cli.engine.SearchConfig().Exploration)
+	}
+
+	// The tokens of a multi-word name are joined, not cut off after the first one
+	err := cli.parseArgument("setoption name Rollout Policy value uniform")
+	if err == nil || !strings.Contains(err.Error(), "Unknown option Rollout Policy") {
+		t.Errorf("err=%v, want the unknown 'Rollout Policy' option", err)
+	}
+}
+
+func TestCliTestGames(t *testing.T) {
+	tests := []struct {
+		command string
+		valid   bool
+	}{
+		{"test rollout 2", true},
+		{"test rollout 0", false},
+		{"test rollout -1", false},
+		{"test rollout", false},
+		{"test halving 0 100", false},
+		{"test halving 10 0", false},
+		{"test halving 10 -1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cli := NewCliIO(strings.NewReader(""), &bytes.Buffer{})
+			if err := cli.parseArgument(tt.command); (err == nil) != tt.valid {
+				t.Errorf("err=%v, valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCliPositionMoves(t *testing.T) {
+	const mate = "xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1"
+	tests := []struct {
+		command string
+		base    string // position before the moves
+		plies   int
+		err     string // expected error, empty if the command is valid
+	}{
+		{"position startpos moves B2b2 B2a1 A1c3", "startpos", 3, ""},
+		{"position startpos moves", "startpos", 0, ""},
+		{"position startpos moves 40 3,3 A3c3", "startpos", 3, ""},
+		{"position " + mate + " moves B3b3", mate, 1, ""},
+		{"position startpos moves B2b2 C3a1", "", 0, "Illegal move C3a1 at ply 2"},
+		{"position startpos moves B2b2 B2a1 X9", "", 0, `Invalid move "X9" at ply 3`},
+		{"position " + mate + " moves B3b3 A1a1", "", 0, "Move A1a1 at ply 2, after the game has ended"},
+		{"position startpos B2b2", "", 0, "Invalid number of sections"},
+		{"makemove A1b2 C3a1", "", 0, "Illegal move C3a1 at ply 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cli := NewCliIO(strings.NewReader(""), &bytes.Buffer{})
+			_ = cli.parseArgument("position startpos moves A1a1")
+			before := cli.engine.Position().Notation()
+
+			err := cli.parseArgument(tt.command)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err=%v, want %q", err, tt.err)
+				}
+				if notation := cli.engine.Position().Notation(); notation != before {
+					t.Errorf("Position changed to %s after the error, want=%s", notation, before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Moves are kept in the history
+			if size := cli.engine.Position().stateList.ValidSize(); size != tt.plies {
+				t.Fatalf("History size=%d, want=%d", size, tt.plies)
+			}
+			for range tt.plies {
+				_ = cli.parseArgument("undomove")
+			}
+			base, _ := FromNotation(tt.base)
+			if notation := cli.engine.Position().Notation(); notation != base.Notation() {
+				t.Errorf("After undoing the moves=%s, want=%s", notation, base.Notation())
+			}
+		})
+	}
+}
+
+func TestCliMoveFormat(t *testing.T) {
+	session := newCliSession(t)
+	session.send("setoption name MoveFormat value codingame")
+	session.send("position startpos moves 4 4 3 3")
+	session.send("go cycles 500")
+	lines := session.expect("bestmove")
+
+	pos, _ := FromNotation(StartingPosition)
+	pos.MakeMove(MoveFromString("B2b2"))
+	pos.MakeMove(MoveFromString("B2a3"))
+
+	// Every move is written as a pair of tokens: <row> <col>
+	info, bestmove := strings.Fields(lines[len(lines)-2]), strings.Fields(lines[len(lines)-1])
+	pv := info[slices.Index(info, "pv")+1:]
+	if len(bestmove) != 3 || len(pv)%2 != 0 || bestmove[1]+" "+bestmove[2] != pv[0]+" "+pv[1] {
+		t.Fatalf("Expected codingame moves, got %q", lines[len(lines)-2:])
+	}
+	if move, err := ParseMove(bestmove[1]+" "+bestmove[2], FormatCodinGame); err != nil || !pos.IsLegal(move) {
+		t.Errorf("Illegal best move %q (err=%v)", lines[len(lines)-1], err)
+	}
+
+	cli := NewCliIO(strings.NewReader(""), &bytes.Buffer{})
+	_ = cli.parseArgument("setoption name MoveFormat value codingame")
+	if err := cli.parseArgument("position startpos moves 4 4 3"); err == nil {
+		t.Error("Expected an error for the unpaired row")
+	}
+}
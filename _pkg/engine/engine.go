@@ -1,6 +1,10 @@
 package uttt
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
 	"uttt/_pkg/mcts"
 )
 
@@ -9,25 +13,115 @@ Main engine class, allowing user to make moves on the board,
 search best move, based on given parameteres
 */
 type Engine struct {
-	mcts   *UtttMCTS
-	policy mcts.BestChildPolicy
+	mcts         *UtttMCTS
+	options      Options
+	limitOptions limitOptions
+	moveFormat   MoveFormat
 }
 
-// Initialize the package
+var _initOnce sync.Once
+
+// Initialize the package, safe to call multiple times
 func Init() {
-	_InitHashing()
+	_initOnce.Do(_InitHashing)
 }
 
 // Get new engine instance
 func NewEngine() *Engine {
-	return &Engine{
-		mcts:   NewUtttMCTS(*NewPosition()),
-		policy: mcts.BestChildMostVisits,
+	e := &Engine{
+		mcts: NewUtttMCTS(*NewPosition()),
+	}
+	e.addOptions()
+	return e
+}
+
+// Get the engine's options, see SetOption
+func (e *Engine) Options() *Options {
+	return &e.options
+}
+
+// Set the option by its name (case-insensitive), for example "Threads"
+func (e *Engine) SetOption(name, value string) error {
+	return e.options.Set(name, value)
+}
+
+// Notation of the moves printed by the engine's users (set by the MoveFormat option)
+func (e *Engine) MoveFormat() MoveFormat {
+	return e.moveFormat
+}
+
+// Get the default limits of the search, with the options (threads, memory and multipv) applied
+func (e *Engine) NewLimits() *mcts.Limits {
+	limits := mcts.DefaultLimits().SetThreads(e.limitOptions.threads).SetMultiPv(e.limitOptions.multipv)
+	if e.limitOptions.mbsize > 0 {
+		limits.SetMbSize(e.limitOptions.mbsize)
 	}
+	return limits
 }
 
 func (e *Engine) SetBestChildPolicy(policy mcts.BestChildPolicy) {
-	e.policy = policy
+	cfg := e.mcts.Config()
+	cfg.BestChild = policy
+	e.mcts.SetConfig(cfg)
+}
+
+// Set the search parameters of this engine (exploration, virtual loss, etc.),
+// those aren't shared with the other engines, see mcts.SearchConfig
+func (e *Engine) SetSearchConfig(cfg mcts.SearchConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	e.mcts.SetConfig(cfg)
+	return nil
+}
+
+func (e *Engine) SearchConfig() mcts.SearchConfig {
+	return e.mcts.Config()
+}
+
+// Enable or disable the MCTS-Solver (enabled by default)
+func (e *Engine) SetSolver(solver bool) {
+	e.mcts.SetSolver(solver)
+}
+
+// Set the contempt used when choosing the best move, positive values make the engine
+// avoid the draws, negative ones prefer them (see mcts.SetContempt)
+func (e *Engine) SetContempt(contempt float64) {
+	e.mcts.SetContempt(contempt)
+}
+
+// Set the node selection policy of the search, for example mcts.UCB1 or mcts.PUCT
+func (e *Engine) SetSelectionPolicy(policy mcts.SelectionPolicy[PosType]) {
+	e.mcts.SetSelectionPolicy(policy)
+}
+
+// Set how much the heuristic evaluation is used instead of the random rollouts,
+// 0 - only rollouts (default), 1 - only the evaluation, see mcts.SetEvalMix
+func (e *Engine) SetEvalMix(mix float64) {
+	e.mcts.SetEvalMix(mix)
+}
+
+// Enable or disable the RAVE statistics, use with mcts.UCB1RAVE selection policy
+func (e *Engine) SetRave(rave bool) {
+	e.mcts.SetRave(rave)
+}
+
+// Allocate the tree nodes from the arena, this way the tree is recycled after moves,
+// and with the memory limit, the search prunes the least visited subtrees instead of
+// freezing the tree's shape. Resets the tree
+func (e *Engine) SetArena(enabled bool) {
+	e.mcts.SetArena(enabled)
+}
+
+// Merge the transpositions (positions reached by different move orders), so they
+// share the search statistics. Resets the tree
+func (e *Engine) SetTranspositions(enabled bool) {
+	e.mcts.SetTranspositions(enabled)
+}
+
+// Set the rollout policy (uniform by default), see NewRolloutPolicy
+func (e *Engine) SetRolloutPolicy(policy RolloutPolicy) {
+	e.mcts.SetRolloutPolicy(policy)
 }
 
 // Starting seraching for the bestmove
@@ -38,8 +132,14 @@ func (e *Engine) Search() {
 
 // Search the moves, in a blocking way
 func (e *Engine) Think() SearchResult {
-	e.mcts.Search()
-	return e.mcts.SearchResult(e.policy)
+	return e.ThinkContext(context.Background())
+}
+
+// Same as Think, but the search ends early when the context is cancelled,
+// returns the result found so far
+func (e *Engine) ThinkContext(ctx context.Context) SearchResult {
+	e.mcts.SearchContext(ctx)
+	return e.mcts.SearchResult(e.mcts.Config().BestChild)
 }
 
 func (e *Engine) IsThinking() bool {
@@ -55,6 +155,40 @@ func (e *Engine) Position() *Position {
 	return &e.mcts.ops.position
 }
 
+// Make a legal move on the position, the search tree of that move is kept,
+// so the next search continues with the accumulated statistics
+func (e *Engine) MakeMove(move PosType) error {
+	if !e.Position().IsLegal(move) {
+		return fmt.Errorf("Move %s is illegal, possible moves=[%s]", move.String(), e.Position().GenerateMoves().String())
+	}
+
+	e.mcts.MakeMove(move)
+	return nil
+}
+
+// Undo last move, resets the search tree
+func (e *Engine) UndoMove() {
+	e.Position().UndoMove()
+	e.mcts.Reset()
+}
+
+// Set new position, resets the search tree
+func (e *Engine) SetPosition(position Position) {
+	e.mcts.SetPosition(position)
+}
+
+// Save the search tree (with the current position) to 'w'
+func (e *Engine) SaveTree(w io.Writer) error {
+	_, err := e.mcts.WriteTo(w)
+	return err
+}
+
+// Load the search tree saved with SaveTree, sets the position to the tree's root,
+// so the next search continues with the loaded statistics
+func (e *Engine) LoadTree(r io.Reader) error {
+	return e.mcts.LoadTree(r)
+}
+
 // Resets all search cache
 func (e *Engine) NewGame() {
 	e.mcts.Reset()
@@ -65,17 +199,24 @@ func (e *Engine) SetLimits(limits *mcts.Limits) {
 	e.mcts.SetLimits(limits)
 }
 
+// Seed the search, so the same position and limits give the same result (with
+// a single thread and the cycles limit), negative seed means seeded with the time.
+// Sets the seed of the current limits, so call it after SetLimits
+func (e *Engine) SetSeed(seed int64) {
+	e.mcts.Limits().SetSeed(seed)
+}
+
 func (e *Engine) Stop() {
 	e.mcts.Stop()
 }
 
 func (e *Engine) Pv() *MoveList {
-	pv, _, _ := e.mcts.Pv(e.mcts.Root, e.policy, false)
+	pv, _, _ := e.mcts.Pv(e.mcts.Root, e.mcts.Config().BestChild, false)
 	return ToMoveList(pv)
 }
 
 func (e *Engine) MultiPv() []mcts.PvResult[PosType] {
-	return e.mcts.MultiPv(e.policy)
+	return e.mcts.MultiPv(e.mcts.Config().BestChild)
 }
 
 func (e *Engine) Mcts() *UtttMCTS {
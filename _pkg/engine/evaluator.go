@@ -0,0 +1,220 @@
+package uttt
+
+import (
+	"math"
+	"math/bits"
+	"uttt/_pkg/mcts"
+)
+
+// CPU-only heuristic evaluation of the position, used by the MCTS instead of
+// (or mixed with) the random rollouts, see mcts.Evaluator
+
+// Weights of the evaluation features
+const (
+	_evalBigWon       = 1.0  // won small board
+	_evalBigCenterWon = 0.5  // bonus for the center small board
+	_evalBigTwo       = 1.2  // two won small boards in a row, with the third one still open
+	_evalSmallTwo     = 0.15 // two pieces in a row on the unresolved small board
+	_evalSmallCenter  = 0.05 // piece in the center of the unresolved small board
+	_evalFreeChoice   = 0.3  // side to move can play on any board
+	_evalScale        = 2.5  // scale of the sigmoid, converting the score into the win probability
+)
+
+// Weights of the move priors
+const (
+	_priorWinGame     = 10.0 // move wins the whole game
+	_priorWinSmall    = 2.0  // move wins the small board
+	_priorWinBigTwo   = 1.5  // bonus, if that small board makes two in a row on the big board
+	_priorBlock       = 1.0  // move blocks opponent's two in a row on the small board
+	_priorMakeTwo     = 0.4  // move makes two in a row on the small board
+	_priorFreeChoice  = -1.5 // sends the opponent to a resolved board (free choice)
+	_priorGiveWin     = -1.0 // sends the opponent to a board, where he can win it immediately
+	_priorCenter      = 0.3  // center of the small board
+	_priorCorner      = 0.15 // corner of the small board
+	_priorTemperature = 1.0  // softmax temperature
+)
+
+const _cornersMask uint = 0b101000101
+
+// Count 'two in a row' patterns of 'our' pieces, with the third square empty
+func _countOpenTwos(our, their uint) int {
+	count := 0
+	for i := 0; i < 8; i++ {
+		pattern := _winningBitboardPatterns[i]
+		if their&pattern == 0 && bits.OnesCount(our&pattern) == 2 {
+			count++
+		}
+	}
+	return count
+}
+
+// Check if placing a piece on 'square' completes any pattern of 'our' pieces
+func _completesPattern(our uint, square PosType) bool {
+	bb := our | (1 << square)
+	for i := 0; i < 8; i++ {
+		pattern := _winningBitboardPatterns[i]
+		if pattern&(1<<square) != 0 && bb&pattern == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the big board as bitboards of the won small boards, and the drawn ones
+func (pos *Position) bigBitboards() (cross, circle, drawn uint) {
+	for i, state := range pos.bigPositionState {
+		switch state {
+		case PositionCrossWon:
+			cross |= 1 << i
+		case PositionCircleWon:
+			circle |= 1 << i
+		case PositionDraw:
+			drawn |= 1 << i
+		}
+	}
+	return cross, circle, drawn
+}
+
+// Heuristic score of the position for given side, positive if the side is better
+func (pos *Position) heuristicScore(turn TurnType) float64 {
+	us, them := _boolToInt(bool(turn)), _boolToInt(!bool(turn))
+	ourPiece := PieceCircle
+	if turn == CrossTurn {
+		ourPiece = PieceCross
+	}
+
+	score := 0.0
+	for i, state := range pos.bigPositionState {
+		switch state {
+		case PositionUnResolved:
+			our, their := pos.bitboards[us][i], pos.bitboards[them][i]
+			score += _evalSmallTwo * float64(_countOpenTwos(our, their)-_countOpenTwos(their, our))
+			score += _evalSmallCenter * float64(int((our>>4)&1)-int((their>>4)&1))
+		case PositionDraw:
+			continue
+		default:
+			sign := -1.0
+			if (state == PositionCrossWon) == (ourPiece == PieceCross) {
+				sign = 1.0
+			}
+			score += sign * _evalBigWon
+			if i == 4 {
+				score += sign * _evalBigCenterWon
+			}
+		}
+	}
+
+	// Two in a row on the big board, drawn boards block both sides
+	cross, circle, drawn := pos.bigBitboards()
+	our, their := circle, cross
+	if turn == CrossTurn {
+		our, their = cross, circle
+	}
+	score += _evalBigTwo * float64(_countOpenTwos(our, their|drawn)-_countOpenTwos(their, our|drawn))
+
+	if pos.nextBigIndex == PosIndexIllegal {
+		score += _evalFreeChoice
+	}
+
+	return score
+}
+
+// Evaluate the position, the value is the win probability of the side to move,
+// the priors are a softmax over the heuristic move scores (see mcts.Evaluator)
+func (ops *UtttOperations) Evaluate(node *mcts.NodeBase[PosType]) (mcts.Result, []float32) {
+	pos := &ops.position
+	if pos.IsTerminated() {
+		return terminalResult(pos), nil
+	}
+
+	value := 1 / (1 + math.Exp(-pos.heuristicScore(pos.Turn())/_evalScale))
+	if len(node.Children) == 0 {
+		return mcts.Result(value), nil
+	}
+
+	priors := make([]float32, len(node.Children))
+	scores := make([]float64, len(node.Children))
+	maxScore := math.Inf(-1)
+	for i := range node.Children {
+		scores[i] = pos.moveScore(node.Children[i].NodeSignature)
+		maxScore = math.Max(maxScore, scores[i])
+	}
+
+	// Softmax
+	sum := 0.0
+	for i := range scores {
+		scores[i] = math.Exp((scores[i] - maxScore) / _priorTemperature)
+		sum += scores[i]
+	}
+	for i := range scores {
+		priors[i] = float32(scores[i] / sum)
+	}
+
+	return mcts.Result(value), priors
+}
+
+// Heuristic score of the move, for the side to move
+func (pos *Position) moveScore(move PosType) float64 {
+	bi, si := move.BigIndex(), move.SmallIndex()
+	us, them := _boolToInt(bool(pos.Turn())), _boolToInt(!bool(pos.Turn()))
+	our, their := pos.bitboards[us][bi], pos.bitboards[them][bi]
+	score := 0.0
+
+	winsSmall := _completesPattern(our, si)
+	if winsSmall {
+		score += _priorWinSmall
+
+		// See if this board makes two in a row on the big board
+		cross, circle, drawn := pos.bigBitboards()
+		bigOur, bigTheir := circle, cross|drawn
+		if us == 1 {
+			bigOur, bigTheir = cross, circle|drawn
+		}
+		if _completesPattern(bigOur, bi) {
+			return _priorWinGame
+		}
+		if _countOpenTwos(bigOur|(1<<bi), bigTheir) > _countOpenTwos(bigOur, bigTheir) {
+			score += _priorWinBigTwo
+		}
+	} else {
+		if _completesPattern(their, si) {
+			score += _priorBlock
+		}
+		if _countOpenTwos(our|(1<<si), their) > _countOpenTwos(our, their) {
+			score += _priorMakeTwo
+		}
+	}
+
+	if si == 4 {
+		score += _priorCenter
+	} else if _cornersMask&(1<<si) != 0 {
+		score += _priorCorner
+	}
+
+	// Where the opponent goes next
+	if pos.sendsToFreeChoice(move) {
+		score += _priorFreeChoice
+	} else {
+		oppOur, oppTheir := pos.bitboards[them][si], pos.bitboards[us][si]
+		if si == bi {
+			oppTheir |= 1 << si
+		}
+		if _countOpenTwos(oppOur, oppTheir) > 0 {
+			score += _priorGiveWin
+		}
+	}
+
+	return score
+}
+
+// Result of the terminated position, from the perspective of the side to move
+func terminalResult(pos *Position) mcts.Result {
+	switch t := pos.Termination(); {
+	case t == TerminationCircleWon && pos.Turn() == CircleTurn,
+		t == TerminationCrossWon && pos.Turn() == CrossTurn:
+		return 1.0
+	case t == TerminationDraw:
+		return 0.5
+	}
+	return 0.0
+}
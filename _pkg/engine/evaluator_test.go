@@ -0,0 +1,113 @@
+package uttt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"uttt/_pkg/mcts"
+)
+
+func TestEvaluatorPriors(t *testing.T) {
+	// Position with the best move, that wins the small board (and the game)
+	notations := []string{
+		"xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1",
+		StartingPosition,
+		"9/9/9/7x1/4xo3/8x/9/4o4/o8 x -",
+	}
+	bestmoves := []PosType{MoveFromString("B3b3"), PosIllegal, PosIllegal}
+
+	for i, notation := range notations {
+		t.Run(fmt.Sprintf("Priors-%s", strings.ReplaceAll(notation, "/", "|")), func(t *testing.T) {
+			pos, err := FromNotation(notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ops := newUtttOps(*pos)
+			root := &mcts.NodeBase[PosType]{}
+			ops.ExpandNode(root)
+
+			value, priors := ops.Evaluate(root)
+			if value <= 0 || value >= 1 {
+				t.Errorf("Value=%f, should be in range (0, 1)", value)
+			}
+			if len(priors) != len(root.Children) {
+				t.Fatalf("Got %d priors, want=%d", len(priors), len(root.Children))
+			}
+
+			sum := float32(0)
+			best := 0
+			for j, p := range priors {
+				sum += p
+				if p > priors[best] {
+					best = j
+				}
+			}
+			if math.Abs(float64(sum-1)) > 1e-4 {
+				t.Errorf("Priors sum=%f, want=1", sum)
+			}
+			if bestmoves[i] != PosIllegal && root.Children[best].NodeSignature != bestmoves[i] {
+				t.Errorf("Highest prior move=%v, want=%v", root.Children[best].NodeSignature, bestmoves[i])
+			}
+
+			// Leaf evaluation has no priors
+			if _, leafPriors := ops.Evaluate(&mcts.NodeBase[PosType]{}); leafPriors != nil {
+				t.Error("Leaf node shouldn't get the priors")
+			}
+		})
+	}
+}
+
+func TestEvaluatorSymmetry(t *testing.T) {
+	// The same position, but with the sides swapped should have 1 - value
+	pos, _ := FromNotation("xxx6/9/9/9/4o4/9/9/9/9 o -")
+	swapped, _ := FromNotation("ooo6/9/9/9/4x4/9/9/9/9 x -")
+
+	value, _ := newUtttOps(*pos).Evaluate(&mcts.NodeBase[PosType]{})
+	swappedValue, _ := newUtttOps(*swapped).Evaluate(&mcts.NodeBase[PosType]{})
+
+	if math.Abs(float64(value-swappedValue)) > 1e-9 {
+		t.Errorf("Value=%f, swapped=%f, should be equal", value, swappedValue)
+	}
+	if value >= 0.5 {
+		t.Errorf("Side down a board should have value < 0.5, got=%f", value)
+	}
+}
+
+func TestPUCTSearch(t *testing.T) {
+	mixes := []float64{1.0, 0.5}
+
+	for _, mix := range mixes {
+		t.Run(fmt.Sprintf("PUCT-mix=%.1f", mix), func(t *testing.T) {
+			engine := NewEngine()
+			engine.SetSelectionPolicy(mcts.PUCT)
+			engine.SetEvalMix(mix)
+			engine.SetLimits(mcts.DefaultLimits().SetThreads(2).SetCycles(5000))
+
+			result, ok := engine.Think().MainLine()
+			if !ok || len(result.Pv) == 0 {
+				t.Fatal("Pv shouldn't be empty after search")
+			}
+			if !engine.Position().IsLegal(result.Bestmove) {
+				t.Errorf("Bestmove %v is illegal", result.Bestmove)
+			}
+
+			// Priors should be set by the evaluator
+			root := engine.Mcts().Root
+			for i := range root.Children {
+				if root.Children[i].Prior() == 0 {
+					t.Fatalf("Child %d has no prior", i)
+				}
+			}
+
+			// Mate should still be found
+			if err := engine.SetNotation("xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1"); err != nil {
+				t.Fatal(err)
+			}
+			if mate, _ := engine.Think().MainLine(); mate.ScoreType != MateScore || mate.Value != 1 {
+				t.Errorf("Expected mate in 1, got=%v", mate)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package uttt
+
+import "math/rand"
+
+// Play 'games' games between the engines (with their own limits, set beforehand), from
+// given position. Every opening (of 'openingPlies' random moves) is played twice, with
+// the engines switching sides, so with the same cycle limit the difference comes only
+// from the search settings. Returns the number of 'a' wins, draws and 'b' wins
+func PlaySearchMatch(start *Position, a, b *Engine, games, openingPlies int, random *rand.Rand) (winsA, draws, winsB int) {
+	pos := start.Clone()
+	opening := 0
+	for game := 0; game < games; game++ {
+		// Play the new opening every other game
+		if game%2 == 0 {
+			for range opening {
+				pos.UndoMove()
+			}
+			opening = 0
+			for opening < openingPlies && !pos.IsTerminated() {
+				moves := pos.GenerateMoves().Slice()
+				pos.MakeMove(moves[random.Intn(len(moves))])
+				opening++
+			}
+		}
+
+		// Side to move in the opening plays with 'a' in even games
+		aTurn := pos.Turn()
+		if game%2 == 1 {
+			aTurn = !aTurn
+		}
+
+		moveCount := 0
+		for !pos.IsTerminated() {
+			engine := b
+			if pos.Turn() == aTurn {
+				engine = a
+			}
+			engine.SetPosition(pos.Clone())
+			engine.Think()
+			pos.MakeMove(engine.Mcts().RootSignature())
+			moveCount++
+		}
+
+		switch t := pos.Termination(); {
+		case t == TerminationDraw:
+			draws++
+		case t == TerminationCrossWon && aTurn == CrossTurn,
+			t == TerminationCircleWon && aTurn == CircleTurn:
+			winsA++
+		default:
+			winsB++
+		}
+
+		for range moveCount {
+			pos.UndoMove()
+		}
+	}
+	return winsA, draws, winsB
+}
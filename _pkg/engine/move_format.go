@@ -0,0 +1,151 @@
+package uttt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Notations of the moves, used by the other UTTT tools. Rows and columns are counted
+// on the whole 9x9 grid, from the top left corner (A3a3 is row 0, column 0):
+//
+//	standard  - B2a1, see PosType.String()
+//	index     - 0-80, row * 9 + col
+//	rowcol    - 4,3
+//	codingame - 4 3, as read and written by the CodinGame's referee
+type MoveFormat int
+
+const (
+	FormatStandard MoveFormat = iota
+	FormatIndex
+	FormatRowCol
+	FormatCodinGame
+	FormatAuto // only when parsing, detects the format of the move
+)
+
+// Names of the formats, accepted by ParseMoveFormat
+var MoveFormatNames = []string{"standard", "index", "rowcol", "codingame", "auto"}
+
+func (f MoveFormat) String() string {
+	if f < 0 || int(f) >= len(MoveFormatNames) {
+		return "unknown"
+	}
+	return MoveFormatNames[f]
+}
+
+// Get the move format by its name (case-insensitive)
+func ParseMoveFormat(name string) (MoveFormat, error) {
+	for i, formatName := range MoveFormatNames {
+		if strings.EqualFold(name, formatName) {
+			return MoveFormat(i), nil
+		}
+	}
+	return FormatStandard, fmt.Errorf("Unknown move format %q, expected one of %v", name, MoveFormatNames)
+}
+
+// Syntax of the moves in this format, used in the error messages
+func (f MoveFormat) Syntax() string {
+	switch f {
+	case FormatStandard:
+		return "[A-C][1-3][a-c][1-3]"
+	case FormatIndex:
+		return "0-80 index"
+	case FormatRowCol:
+		return "<row>,<col>"
+	case FormatCodinGame:
+		return "<row> <col>"
+	}
+	return "[A-C][1-3][a-c][1-3], 0-80 index or <row>,<col>"
+}
+
+// Global row and column of the move on the 9x9 grid
+func (pos PosType) RowCol() (int, int) {
+	bi, si := int(pos.BigIndex()), int(pos.SmallIndex())
+	return (bi/3)*3 + si/3, (bi%3)*3 + si%3
+}
+
+// Create a move from the global row and column, both in [0, 8]
+func MoveFromRowCol(row, col int) PosType {
+	if row < 0 || row >= 9 || col < 0 || col >= 9 {
+		return PosIllegal
+	}
+	return MakeMove((row/3)*3+col/3, (row%3)*3+col%3)
+}
+
+// Detect the format of the move, by its characters
+func DetectMoveFormat(str string) (MoveFormat, error) {
+	switch {
+	case len(str) == 4 && str[0] >= 'A' && str[0] <= 'Z':
+		return FormatStandard, nil
+	case strings.Contains(str, ","):
+		return FormatRowCol, nil
+	case len(strings.Fields(str)) == 2:
+		return FormatCodinGame, nil
+	case str != "" && strings.Trim(str, "0123456789") == "":
+		return FormatIndex, nil
+	}
+	return FormatAuto, fmt.Errorf("Invalid move %q, expected %s", str, FormatAuto.Syntax())
+}
+
+// Parse the move in given format, FormatAuto detects it
+func ParseMove(str string, format MoveFormat) (PosType, error) {
+	if format == FormatAuto {
+		var err error
+		if format, err = DetectMoveFormat(str); err != nil {
+			return PosIllegal, err
+		}
+	}
+
+	move := PosIllegal
+	switch format {
+	case FormatStandard:
+		move = MoveFromString(str)
+	case FormatIndex:
+		if index, err := strconv.Atoi(str); err == nil && index >= 0 && index < 81 {
+			move = MoveFromRowCol(index/9, index%9)
+		}
+	case FormatRowCol, FormatCodinGame:
+		sep := ","
+		if format == FormatCodinGame {
+			sep = " "
+		}
+		rowStr, colStr, found := strings.Cut(strings.TrimSpace(str), sep)
+		row, rowErr := strconv.Atoi(strings.TrimSpace(rowStr))
+		col, colErr := strconv.Atoi(strings.TrimSpace(colStr))
+		if found && rowErr == nil && colErr == nil {
+			move = MoveFromRowCol(row, col)
+		}
+	}
+
+	if move == PosIllegal {
+		return PosIllegal, fmt.Errorf("Invalid move %q, expected %s", str, format.Syntax())
+	}
+	return move, nil
+}
+
+// Get string representation of the move in given format, FormatAuto gives the standard one
+func FormatMove(move PosType, format MoveFormat) string {
+	if move.SmallIndex() >= 9 || move.BigIndex() >= 9 {
+		return "(none)"
+	}
+
+	row, col := move.RowCol()
+	switch format {
+	case FormatIndex:
+		return strconv.Itoa(row*9 + col)
+	case FormatRowCol:
+		return fmt.Sprintf("%d,%d", row, col)
+	case FormatCodinGame:
+		return fmt.Sprintf("%d %d", row, col)
+	}
+	return move.String()
+}
+
+// Format all of the moves, see FormatMove
+func FormatMoves(moves []PosType, format MoveFormat) []string {
+	strMoves := make([]string, len(moves))
+	for i, move := range moves {
+		strMoves[i] = FormatMove(move, format)
+	}
+	return strMoves
+}
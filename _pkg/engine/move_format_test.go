@@ -0,0 +1,79 @@
+package uttt
+
+import "testing"
+
+func TestMoveFormats(t *testing.T) {
+	tests := []struct {
+		move   string
+		format MoveFormat
+		want   string
+	}{
+		{"A3a3", FormatIndex, "0"},
+		{"A3a3", FormatRowCol, "0,0"},
+		{"B2b2", FormatIndex, "40"},
+		{"B2a1", FormatRowCol, "5,3"},
+		{"B2a1", FormatCodinGame, "5 3"},
+		{"C1c1", FormatIndex, "80"},
+		{"C3a2", FormatCodinGame, "1 6"},
+		{"A1c3", FormatStandard, "A1c3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.move+" "+tt.format.String(), func(t *testing.T) {
+			move := MoveFromString(tt.move)
+			if got := FormatMove(move, tt.format); got != tt.want {
+				t.Fatalf("FormatMove=%q, want=%q", got, tt.want)
+			}
+			for _, format := range []MoveFormat{tt.format, FormatAuto} {
+				if parsed, err := ParseMove(tt.want, format); err != nil || parsed != move {
+					t.Errorf("ParseMove(%q, %s)=%s, err=%v, want=%s", tt.want, format, parsed, err, tt.move)
+				}
+			}
+		})
+	}
+
+	// Every move survives the conversion
+	for bi := range 9 {
+		for si := range 9 {
+			move := MakeMove(bi, si)
+			for _, format := range []MoveFormat{FormatStandard, FormatIndex, FormatRowCol, FormatCodinGame} {
+				if parsed, err := ParseMove(FormatMove(move, format), format); err != nil || parsed != move {
+					t.Fatalf("Move %s in %s format=%s, err=%v", move, format, parsed, err)
+				}
+			}
+		}
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	tests := []struct {
+		str    string
+		format MoveFormat
+	}{
+		{"81", FormatIndex},
+		{"-1", FormatIndex},
+		{"B2a1", FormatIndex},
+		{"9,0", FormatRowCol},
+		{"4 4", FormatRowCol},
+		{"4,", FormatRowCol},
+		{"4", FormatCodinGame},
+		{"D1a1", FormatStandard},
+		{"", FormatAuto},
+		{"x", FormatAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str+" "+tt.format.String(), func(t *testing.T) {
+			if move, err := ParseMove(tt.str, tt.format); err == nil {
+				t.Errorf("Expected an error, got %s", move)
+			}
+		})
+	}
+
+	if _, err := ParseMoveFormat("CodinGame"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseMoveFormat("algebraic"); err == nil {
+		t.Error("Expected an error for the unknown format")
+	}
+}
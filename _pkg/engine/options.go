@@ -0,0 +1,214 @@
+package uttt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"uttt/_pkg/mcts"
+)
+
+// Engine options, settable by their names (used by the 'setoption' command),
+// each one calls its change callback with the parsed value
+
+type OptionType string
+
+const (
+	OptionSpin  OptionType = "spin"  // integer in [Min, Max]
+	OptionFloat OptionType = "float" // floating point number in [Min, Max]
+	OptionCheck OptionType = "check" // true or false
+	OptionCombo OptionType = "combo" // one of the Vars
+)
+
+type Option struct {
+	Name    string     `json:"name"`
+	Type    OptionType `json:"type"`
+	Default string     `json:"default"`
+	Min     float64    `json:"min"`
+	Max     float64    `json:"max"`
+	Vars    []string   `json:"vars,omitempty"`
+
+	value    string
+	onChange func(value string) error // called with the validated value
+}
+
+// Current value of the option
+func (o *Option) Value() string {
+	return o.value
+}
+
+// Option in the protocol's format, for example:
+// option name Threads type spin default 1 min 1 max 256
+func (o Option) String() string {
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "option name %s type %s default %s", o.Name, o.Type, o.Default)
+	switch o.Type {
+	case OptionSpin, OptionFloat:
+		fmt.Fprintf(&builder, " min %v max %v", o.Min, o.Max)
+	case OptionCombo:
+		for _, v := range o.Vars {
+			builder.WriteString(" var " + v)
+		}
+	}
+	return builder.String()
+}
+
+// Check if the value matches the option's type (and range)
+func (o *Option) validate(value string) error {
+	inRange := func(v float64) error {
+		if v < o.Min || v > o.Max {
+			return fmt.Errorf("Invalid value of %s: %s, expected a number in [%v, %v]", o.Name, value, o.Min, o.Max)
+		}
+		return nil
+	}
+
+	switch o.Type {
+	case OptionSpin:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value of %s: %s, expected an integer", o.Name, value)
+		}
+		return inRange(float64(n))
+	case OptionFloat:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid value of %s: %s, expected a number", o.Name, value)
+		}
+		return inRange(v)
+	case OptionCheck:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("Invalid value of %s: %s, expected true or false", o.Name, value)
+		}
+	case OptionCombo:
+		if !slices.Contains(o.Vars, value) {
+			return fmt.Errorf("Invalid value of %s: %s, expected one of %v", o.Name, value, o.Vars)
+		}
+	}
+	return nil
+}
+
+// Registry of the options, in the listing order
+type Options struct {
+	list []*Option
+}
+
+// Add the option, its value is set to the default one (without calling the callback)
+func (opts *Options) Add(option Option, onChange func(value string) error) {
+	option.value = option.Default
+	option.onChange = onChange
+	opts.list = append(opts.list, &option)
+}
+
+// Get the option by its name (case-insensitive)
+func (opts *Options) Get(name string) (*Option, bool) {
+	for _, option := range opts.list {
+		if strings.EqualFold(option.Name, name) {
+			return option, true
+		}
+	}
+	return nil, false
+}
+
+// Set the option's value, the name is case-insensitive
+func (opts *Options) Set(name, value string) error {
+	option, ok := opts.Get(name)
+	if !ok {
+		return fmt.Errorf("Unknown option %s", name)
+	}
+	if err := option.validate(value); err != nil {
+		return err
+	}
+	if err := option.onChange(value); err != nil {
+		return err
+	}
+	option.value = value
+	return nil
+}
+
+// Copy of the options, in the listing order
+func (opts *Options) List() []Option {
+	list := make([]Option, len(opts.list))
+	for i, option := range opts.list {
+		list[i] = *option
+	}
+	return list
+}
+
+// Limits of the engine's searches, set by the options
+type limitOptions struct {
+	threads int
+	mbsize  int // 0 - no memory limit
+	multipv int
+}
+
+const (
+	MaxThreadsOption = 256
+	MaxHashOption    = 1 << 16 // MB
+	MaxMultiPvOption = 81
+)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Register the engine's options
+func (e *Engine) addOptions() {
+	e.limitOptions = limitOptions{threads: 1, multipv: 1}
+	spin := func(target *int) func(string) error {
+		return func(value string) error {
+			*target, _ = strconv.Atoi(value)
+			return nil
+		}
+	}
+
+	e.options.Add(Option{Name: "Threads", Type: OptionSpin, Default: "1", Min: 1, Max: MaxThreadsOption}, spin(&e.limitOptions.threads))
+	e.options.Add(Option{Name: "Hash", Type: OptionSpin, Default: "0", Min: 0, Max: MaxHashOption}, spin(&e.limitOptions.mbsize))
+	e.options.Add(Option{Name: "MultiPV", Type: OptionSpin, Default: "1", Min: 1, Max: MaxMultiPvOption}, spin(&e.limitOptions.multipv))
+
+	// Search parameters, set by their names in the mcts.SearchConfig
+	config := func(name string) func(string) error {
+		return func(value string) error {
+			cfg := e.SearchConfig()
+			if err := cfg.Set(name, value); err != nil {
+				return err
+			}
+			return e.SetSearchConfig(cfg)
+		}
+	}
+	cfg := mcts.DefaultSearchConfig()
+	e.options.Add(Option{Name: "Exploration", Type: OptionFloat, Default: formatFloat(cfg.Exploration), Min: 0, Max: 10}, config("exploration"))
+	e.options.Add(Option{Name: "Puct", Type: OptionFloat, Default: formatFloat(cfg.Puct), Min: 0, Max: 10}, config("puct"))
+	e.options.Add(Option{Name: "RaveEquivalence", Type: OptionFloat, Default: formatFloat(cfg.RaveEquivalence), Min: 1, Max: 1e6}, config("rave"))
+	e.options.Add(Option{Name: "VirtualLoss", Type: OptionSpin, Default: fmt.Sprint(cfg.VirtualLoss), Min: 0, Max: 100}, config("virtualloss"))
+	e.options.Add(Option{Name: "FPU", Type: OptionFloat, Default: formatFloat(cfg.FPU), Min: -1, Max: 1}, config("fpu"))
+	e.options.Add(Option{Name: "BestChild", Type: OptionCombo, Default: cfg.BestChild.String(), Vars: []string{"visits", "winrate"}}, config("bestchild"))
+	e.options.Add(Option{Name: "ThreadScaling", Type: OptionCheck, Default: "false"}, config("threadscaling"))
+	e.options.Add(Option{Name: "Contempt", Type: OptionFloat, Default: "0", Min: -0.5, Max: 0.5}, config("contempt"))
+	e.options.Add(Option{Name: "Policy", Type: OptionCombo, Default: "ucb1", Vars: mcts.SelectionPolicyNames}, config("policy"))
+	e.options.Add(Option{Name: "MoveOverhead", Type: OptionSpin, Default: fmt.Sprint(cfg.MoveOverhead), Min: 0, Max: 5000}, config("moveoverhead"))
+	e.options.Add(Option{Name: "DefaultMovesToGo", Type: OptionSpin, Default: fmt.Sprint(cfg.DefaultMovesToGo), Min: 1, Max: 100}, config("defaultmovestogo"))
+
+	e.options.Add(Option{Name: "RolloutPolicy", Type: OptionCombo, Default: "uniform", Vars: RolloutPolicyNames}, func(value string) error {
+		policy, err := NewRolloutPolicy(value)
+		if err == nil {
+			e.SetRolloutPolicy(policy)
+		}
+		return err
+	})
+	e.options.Add(Option{Name: "EvalMix", Type: OptionFloat, Default: "0", Min: 0, Max: 1}, func(value string) error {
+		mix, _ := strconv.ParseFloat(value, 64)
+		e.SetEvalMix(mix)
+		return nil
+	})
+	e.options.Add(Option{Name: "Solver", Type: OptionCheck, Default: "true"}, func(value string) error {
+		e.SetSolver(value == "true")
+		return nil
+	})
+	e.options.Add(Option{Name: "MoveFormat", Type: OptionCombo, Default: "standard", Vars: MoveFormatNames[:FormatAuto]}, func(value string) error {
+		format, err := ParseMoveFormat(value)
+		if err == nil {
+			e.moveFormat = format
+		}
+		return err
+	})
+}
@@ -0,0 +1,105 @@
+package uttt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptionsSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"Threads", "8", true},
+		{"threads", "8", true},
+		{"Threads", "0", false},
+		{"Threads", "1.5", false},
+		{"Hash", "0", true},
+		{"Exploration", "1.25", true},
+		{"Exploration", "-1", false},
+		{"FPU", "0.5", true},
+		{"BestChild", "winrate", true},
+		{"BestChild", "lcb", false},
+		{"ThreadScaling", "true", true},
+		{"ThreadScaling", "yes", false},
+		{"Policy", "ucb1tuned", true},
+		{"RolloutPolicy", "tactical", true},
+		{"EvalMix", "2", false},
+		{"Unknown", "1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			engine := NewEngine()
+			err := engine.SetOption(tt.name, tt.value)
+			if (err == nil) != tt.valid {
+				t.Fatalf("err=%v, valid=%v", err, tt.valid)
+			}
+
+			option, ok := engine.Options().Get(tt.name)
+			if !ok {
+				return
+			}
+			want := option.Default
+			if tt.valid {
+				want = tt.value
+			}
+			if option.Value() != want {
+				t.Errorf("Value=%s, want=%s", option.Value(), want)
+			}
+		})
+	}
+}
+
+func TestOptionsApply(t *testing.T) {
+	engine := NewEngine()
+	for name, value := range map[string]string{
+		"Threads": "4", "Hash": "8", "MultiPV": "3", "Exploration": "1.5", "BestChild": "winrate", "Solver": "false",
+	} {
+		if err := engine.SetOption(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	limits := engine.NewLimits()
+	if limits.NThreads != 4 || limits.ByteSize != 8<<20 || limits.MultiPv != 3 {
+		t.Errorf("Limits threads=%d bytesize=%d multipv=%d, want=4, %d, 3", limits.NThreads, limits.ByteSize, limits.MultiPv, 8<<20)
+	}
+	if cfg := engine.SearchConfig(); cfg.Exploration != 1.5 || cfg.BestChild.String() != "winrate" {
+		t.Errorf("Config=%v, want exploration=1.5 and winrate best child", cfg)
+	}
+	if engine.Mcts().Solver() {
+		t.Error("Solver should be disabled")
+	}
+
+	// Other engines keep the defaults
+	if limits := NewEngine().NewLimits(); limits.NThreads != 1 || !limits.InfiniteSize() {
+		t.Errorf("New engine's limits=%v, want the defaults", limits)
+	}
+}
+
+func TestOptionsDescription(t *testing.T) {
+	options := NewEngine().Options().List()
+	for _, option := range options {
+		if err := option.validate(option.Default); err != nil {
+			t.Errorf("Invalid default of %s: %v", option.Name, err)
+		}
+	}
+
+	if threads := options[0].String(); threads != "option name Threads type spin default 1 min 1 max 256" {
+		t.Errorf("Threads=%q", threads)
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != len(options) {
+		t.Fatalf("Decoded %d options, want=%d (err=%v)", len(decoded), len(options), err)
+	}
+	if decoded[0]["name"] != "Threads" || decoded[0]["type"] != "spin" || decoded[0]["max"] != float64(MaxThreadsOption) {
+		t.Errorf("Threads option's JSON=%v", decoded[0])
+	}
+}
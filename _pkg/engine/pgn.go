@@ -0,0 +1,743 @@
+package uttt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Game records in the UTTT-PGN format, based on the chess' PGN (see docs/pgn.md):
+//
+//	[Event "Casual game"]
+//	[Cross "Alice"]
+//	[Circle "Bob"]
+//	[Result "1-0"]
+//
+//	1. B2b2 {[%eval 0.55] center} B2a1 (1... B2c3 2. C3c3) 2. A1c3 1-0
+//
+// The moves are validated, so every record can be replayed
+
+// Names of the tags with a special meaning
+const (
+	TagResult   = "Result"
+	TagStart    = "Start"    // notation of the starting position, 'startpos' if not given
+	TagNotation = "Notation" // format of the moves (standard, index or rowcol), see MoveFormat
+)
+
+// Game results, written after the moves and in the 'Result' tag
+const (
+	ResultCrossWin  = "1-0"
+	ResultCircleWin = "0-1"
+	ResultDraw      = "1/2-1/2"
+	ResultUnknown   = "*"
+)
+
+type GameTag struct {
+	Name  string
+	Value string
+}
+
+// Evaluation annotation of a move, written as [%eval 0.55] or [%eval #-3] in its comment
+type GameEval struct {
+	Value float64 // expected score of the cross player, in [0, 1]
+	Mate  int     // plies until the game ends, positive if the cross player wins, 0 if it's not a mate
+}
+
+type GameMove struct {
+	Move    PosType
+	Eval    *GameEval // optional
+	Comment string    // comment after the move
+	// Alternatives to this move, played from the position before it
+	Variations []Variation
+}
+
+// Sequence of the moves, either the main line or a variation
+type Variation struct {
+	Comment string // comment before the first move
+	Moves   []GameMove
+}
+
+type Game struct {
+	Tags []GameTag
+	Variation
+}
+
+// Create the game record with the standard tags, in the given position
+// (empty notation for the starting position)
+func NewGame(notation string) *Game {
+	game := &Game{Tags: []GameTag{
+		{"Event", "?"},
+		{"Date", "????.??.??"},
+		{"Cross", "?"},
+		{"Circle", "?"},
+		{TagResult, ResultUnknown},
+	}}
+	if notation != "" && notation != "startpos" {
+		game.SetTag(TagStart, notation)
+	}
+	return game
+}
+
+// Create the game record of the moves played on the position (see Position.History)
+func GameFromPosition(pos *Position) *Game {
+	// Find the starting position, by undoing the moves (the history isn't cloned)
+	history := pos.History()
+	for range history {
+		pos.UndoMove()
+	}
+	start := pos.Notation()
+	for _, move := range history {
+		pos.MakeMove(move)
+	}
+
+	game := NewGame("")
+	if start != StartingPosition {
+		game.SetTag(TagStart, start)
+	}
+	for _, move := range history {
+		game.Moves = append(game.Moves, GameMove{Move: move})
+	}
+
+	switch {
+	case !pos.IsTerminated():
+	case pos.termination == TerminationDraw:
+		game.SetTag(TagResult, ResultDraw)
+	case pos.Turn() == CircleTurn:
+		game.SetTag(TagResult, ResultCrossWin)
+	default:
+		game.SetTag(TagResult, ResultCircleWin)
+	}
+	return game
+}
+
+// Get the tag's value, or an empty string if it's missing
+func (g *Game) Tag(name string) string {
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// Set the tag's value, adds the tag if it's missing
+func (g *Game) SetTag(name, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, GameTag{name, value})
+}
+
+// Result of the game, '*' if it's unknown
+func (g *Game) Result() string {
+	if result := g.Tag(TagResult); result != "" {
+		return result
+	}
+	return ResultUnknown
+}
+
+// Format of the game's moves, set by the Notation tag (standard if it's missing)
+func (g *Game) MoveFormat() (MoveFormat, error) {
+	notation := g.Tag(TagNotation)
+	if notation == "" {
+		return FormatStandard, nil
+	}
+	format, err := ParseMoveFormat(notation)
+	if err != nil {
+		return format, err
+	}
+	// Moves can't contain spaces, and the format must be known to write them
+	if format == FormatCodinGame || format == FormatAuto {
+		return format, fmt.Errorf("Notation %s can't be used in the game records", notation)
+	}
+	return format, nil
+}
+
+// Starting position of the game
+func (g *Game) Start() (*Position, error) {
+	notation := g.Tag(TagStart)
+	if notation == "" {
+		notation = StartingPosition
+	}
+	return FromNotation(notation)
+}
+
+// Position after the main line, its history holds the game's moves
+func (g *Game) Position() (*Position, error) {
+	pos, err := g.Start()
+	if err != nil {
+		return nil, err
+	}
+	for i, move := range g.Moves {
+		if !pos.IsLegal(move.Move) {
+			return nil, fmt.Errorf("Illegal move %s at ply %d", move.Move, i+1)
+		}
+		pos.MakeMove(move.Move)
+	}
+	return pos, nil
+}
+
+// Game record in the UTTT-PGN format
+func (g *Game) String() string {
+	builder := strings.Builder{}
+	_, _ = g.WriteTo(&builder)
+	return builder.String()
+}
+
+// Maximum length of the movetext's lines, longer comments are kept in one line
+const pgnLineLength = 80
+
+// Write the game record, followed by an empty line
+func (g *Game) WriteTo(w io.Writer) (int64, error) {
+	builder := strings.Builder{}
+	for _, tag := range g.Tags {
+		if strings.ContainsAny(tag.Value, "\n\r") {
+			return 0, fmt.Errorf("Tag %s can't contain a new line", tag.Name)
+		}
+		value := strings.ReplaceAll(strings.ReplaceAll(tag.Value, `\`, `\\`), `"`, `\"`)
+		fmt.Fprintf(&builder, "[%s \"%s\"]\n", tag.Name, value)
+	}
+	if len(g.Tags) > 0 {
+		builder.WriteByte('\n')
+	}
+
+	start, err := g.Start()
+	if err != nil {
+		return 0, err
+	}
+	format, err := g.MoveFormat()
+	if err != nil {
+		return 0, err
+	}
+	tokens, err := writeVariation(g.Variation, start.Turn(), 0, format)
+	if err != nil {
+		return 0, err
+	}
+	tokens = append(tokens, g.Result())
+
+	// Wrap the movetext
+	length := 0
+	for i, token := range tokens {
+		if i > 0 && length+1+len(token) > pgnLineLength {
+			builder.WriteByte('\n')
+			length = 0
+		} else if i > 0 {
+			builder.WriteByte(' ')
+			length++
+		}
+		builder.WriteString(token)
+		length += len(token)
+	}
+	builder.WriteString("\n\n")
+
+	n, err := io.WriteString(w, builder.String())
+	return int64(n), err
+}
+
+// Write the games, one after another
+func WriteGames(w io.Writer, games []*Game) error {
+	for _, game := range games {
+		if _, err := game.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tokens of the variation, starting with given side to move at 'ply' (0 - the game's first move)
+func writeVariation(variation Variation, turn TurnType, ply int, format MoveFormat) ([]string, error) {
+	var tokens []string
+	// Full move number, counting from the cross player's move
+	circleStarted := (turn == CircleTurn) != (ply%2 == 1)
+	number := func(ply int) int {
+		if circleStarted {
+			ply++
+		}
+		return ply/2 + 1
+	}
+	// The first move of the circle player starts with the numbered ellipsis
+	needNumber := true
+	addComment := func(comment string) error {
+		if strings.Contains(comment, "}") {
+			return fmt.Errorf("Comment %q can't contain '}'", comment)
+		}
+		tokens = append(tokens, "{"+comment+"}")
+		needNumber = true
+		return nil
+	}
+
+	if variation.Comment != "" {
+		if err := addComment(variation.Comment); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, move := range variation.Moves {
+		side := turn
+		if i%2 == 1 {
+			side = !turn
+		}
+		if side == CrossTurn {
+			tokens = append(tokens, fmt.Sprintf("%d.", number(ply+i)))
+		} else if needNumber {
+			tokens = append(tokens, fmt.Sprintf("%d...", number(ply+i)))
+		}
+		tokens = append(tokens, FormatMove(move.Move, format))
+		needNumber = false
+
+		if move.Eval != nil || move.Comment != "" {
+			comment := move.Comment
+			if move.Eval != nil {
+				comment = strings.TrimSpace(move.Eval.String() + " " + comment)
+			}
+			if err := addComment(comment); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, v := range move.Variations {
+			if len(v.Moves) == 0 {
+				return nil, fmt.Errorf("Empty variation at ply %d", ply+i+1)
+			}
+			inner, err := writeVariation(v, side, ply+i, format)
+			if err != nil {
+				return nil, err
+			}
+			inner[0] = "(" + inner[0]
+			inner[len(inner)-1] += ")"
+			tokens = append(tokens, inner...)
+			needNumber = true
+		}
+	}
+	return tokens, nil
+}
+
+func (e GameEval) String() string {
+	if e.Mate != 0 {
+		return fmt.Sprintf("[%%eval #%d]", e.Mate)
+	}
+	return fmt.Sprintf("[%%eval %s]", strconv.FormatFloat(e.Value, 'g', -1, 64))
+}
+
+// Error of the UTTT-PGN parser, at given position of the input (both starting from 1)
+type PgnError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *PgnError) Error() string {
+	return fmt.Sprintf("pgn: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type pgnTokenType int
+
+const (
+	pgnEOF pgnTokenType = iota
+	pgnSymbol
+	pgnString
+	pgnMoveNumber
+	pgnComment
+	pgnLBracket
+	pgnRBracket
+	pgnLParen
+	pgnRParen
+)
+
+type pgnToken struct {
+	kind   pgnTokenType
+	text   string
+	line   int
+	column int
+}
+
+type pgnLexer struct {
+	input  []rune
+	offset int
+	line   int
+	column int
+	peeked *pgnToken
+}
+
+func (l *pgnLexer) errorf(line, column int, format string, args ...any) error {
+	return &PgnError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Move to the next character
+func (l *pgnLexer) advance() rune {
+	c := l.input[l.offset]
+	l.offset++
+	if c == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return c
+}
+
+func isPgnSymbol(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_+#=:/*-,", c)
+}
+
+func (l *pgnLexer) peek() (pgnToken, error) {
+	if l.peeked == nil {
+		token, err := l.scan()
+		if err != nil {
+			return token, err
+		}
+		l.peeked = &token
+	}
+	return *l.peeked, nil
+}
+
+func (l *pgnLexer) next() (pgnToken, error) {
+	token, err := l.peek()
+	l.peeked = nil
+	return token, err
+}
+
+func (l *pgnLexer) scan() (pgnToken, error) {
+	// Skip the whitespace and the line comments
+	for l.offset < len(l.input) {
+		if c := l.input[l.offset]; c == ';' {
+			for l.offset < len(l.input) && l.input[l.offset] != '\n' {
+				l.advance()
+			}
+		} else if unicode.IsSpace(c) {
+			l.advance()
+		} else {
+			break
+		}
+	}
+
+	token := pgnToken{line: l.line, column: l.column}
+	if l.offset == len(l.input) {
+		return token, nil
+	}
+
+	switch c := l.advance(); c {
+	case '[':
+		token.kind = pgnLBracket
+	case ']':
+		token.kind = pgnRBracket
+	case '(':
+		token.kind = pgnLParen
+	case ')':
+		token.kind = pgnRParen
+	case '{':
+		token.kind = pgnComment
+		start := l.offset
+		for l.offset < len(l.input) && l.input[l.offset] != '}' {
+			l.advance()
+		}
+		if l.offset == len(l.input) {
+			return token, l.errorf(token.line, token.column, "unterminated comment")
+		}
+		token.text = strings.TrimSpace(string(l.input[start:l.offset]))
+		l.advance()
+	case '"':
+		token.kind = pgnString
+		builder := strings.Builder{}
+		for {
+			if l.offset == len(l.input) || l.input[l.offset] == '\n' {
+				return token, l.errorf(token.line, token.column, "unterminated string")
+			}
+			c := l.advance()
+			if c == '"' {
+				break
+			}
+			if c == '\\' && l.offset < len(l.input) && (l.input[l.offset] == '"' || l.input[l.offset] == '\\') {
+				c = l.advance()
+			}
+			builder.WriteRune(c)
+		}
+		token.text = builder.String()
+	default:
+		if !isPgnSymbol(c) {
+			return token, l.errorf(token.line, token.column, "unexpected character %q", c)
+		}
+		start := l.offset - 1
+		for l.offset < len(l.input) && isPgnSymbol(l.input[l.offset]) {
+			l.advance()
+		}
+		token.kind = pgnSymbol
+		token.text = string(l.input[start:l.offset])
+
+		// Move number, followed by one or more dots: 12. or 12...
+		if _, err := strconv.Atoi(token.text); err == nil && l.offset < len(l.input) && l.input[l.offset] == '.' {
+			for l.offset < len(l.input) && l.input[l.offset] == '.' {
+				l.advance()
+			}
+			token.kind = pgnMoveNumber
+		}
+	}
+	return token, nil
+}
+
+func isPgnResult(text string) bool {
+	return text == ResultCrossWin || text == ResultCircleWin || text == ResultDraw || text == ResultUnknown
+}
+
+// Parse all of the games in the UTTT-PGN format
+func ReadGames(r io.Reader) ([]*Game, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lexer := &pgnLexer{input: []rune(string(data)), line: 1, column: 1}
+	var games []*Game
+	for {
+		token, err := lexer.peek()
+		if err != nil {
+			return nil, err
+		}
+		if token.kind == pgnEOF {
+			return games, nil
+		}
+
+		game, err := parseGame(lexer)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+}
+
+// Parse the single game in the UTTT-PGN format
+func ParseGame(record string) (*Game, error) {
+	games, err := ReadGames(strings.NewReader(record))
+	if err != nil {
+		return nil, err
+	}
+	if len(games) != 1 {
+		return nil, fmt.Errorf("pgn: expected 1 game, got %d", len(games))
+	}
+	return games[0], nil
+}
+
+func parseGame(l *pgnLexer) (*Game, error) {
+	game := &Game{}
+	var startTag, notationTag pgnToken
+
+	// Tag pairs
+	for {
+		token, err := l.peek()
+		if err != nil {
+			return nil, err
+		}
+		if token.kind != pgnLBracket {
+			break
+		}
+		l.next()
+
+		name, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if name.kind != pgnSymbol {
+			return nil, l.errorf(name.line, name.column, "expected the tag name")
+		}
+		value, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if value.kind != pgnString {
+			return nil, l.errorf(value.line, value.column, "expected the quoted value of tag %s", name.text)
+		}
+		end, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if end.kind != pgnRBracket {
+			return nil, l.errorf(end.line, end.column, "expected ']' after tag %s", name.text)
+		}
+		if game.Tag(name.text) != "" {
+			return nil, l.errorf(name.line, name.column, "duplicate tag %s", name.text)
+		}
+		switch name.text {
+		case TagStart:
+			startTag = value
+		case TagNotation:
+			notationTag = value
+		}
+		game.Tags = append(game.Tags, GameTag{name.text, value.text})
+	}
+
+	start, err := game.Start()
+	if err != nil {
+		return nil, l.errorf(startTag.line, startTag.column, "invalid %s tag: %v", TagStart, err)
+	}
+
+	// Without the Notation tag, the format of every move is detected
+	format := FormatAuto
+	if game.Tag(TagNotation) != "" {
+		if format, err = game.MoveFormat(); err != nil {
+			return nil, l.errorf(notationTag.line, notationTag.column, "invalid %s tag: %v", TagNotation, err)
+		}
+	}
+
+	game.Variation, err = parseVariation(l, start, format, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Game termination marker, should match the Result tag
+	token, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+	if token.kind != pgnSymbol || !isPgnResult(token.text) {
+		return nil, l.errorf(token.line, token.column, "expected the game result (1-0, 0-1, 1/2-1/2 or *)")
+	}
+	if result := game.Tag(TagResult); result == "" {
+		game.SetTag(TagResult, token.text)
+	} else if result != token.text {
+		return nil, l.errorf(token.line, token.column, "result %s doesn't match the %s tag %s", token.text, TagResult, result)
+	}
+	return game, nil
+}
+
+// Parse the moves played on the position, until the end of the variation (the closing parenthesis,
+// which isn't consumed) or the game result. The position is restored afterwards
+func parseVariation(l *pgnLexer, pos *Position, format MoveFormat, nested bool) (Variation, error) {
+	var variation Variation
+	played := 0
+	defer func() {
+		for range played {
+			pos.UndoMove()
+		}
+	}()
+
+	for {
+		token, err := l.peek()
+		if err != nil {
+			return variation, err
+		}
+
+		switch token.kind {
+		case pgnEOF:
+			if nested {
+				return variation, l.errorf(token.line, token.column, "unterminated variation")
+			}
+			return variation, l.errorf(token.line, token.column, "expected the game result (1-0, 0-1, 1/2-1/2 or *)")
+		case pgnRParen:
+			if !nested {
+				return variation, l.errorf(token.line, token.column, "unexpected ')'")
+			}
+			return variation, nil
+		case pgnMoveNumber:
+			l.next()
+		case pgnComment:
+			l.next()
+			comment, eval, err := parseComment(token.text)
+			if err != nil {
+				return variation, l.errorf(token.line, token.column, "%v", err)
+			}
+			if len(variation.Moves) == 0 {
+				if eval != nil {
+					return variation, l.errorf(token.line, token.column, "eval annotation before the first move")
+				}
+				variation.Comment = joinComments(variation.Comment, comment)
+				continue
+			}
+			last := &variation.Moves[len(variation.Moves)-1]
+			last.Comment = joinComments(last.Comment, comment)
+			if eval != nil {
+				last.Eval = eval
+			}
+		case pgnLParen:
+			l.next()
+			if len(variation.Moves) == 0 {
+				return variation, l.errorf(token.line, token.column, "variation before the first move")
+			}
+
+			// Alternative to the last move
+			pos.UndoMove()
+			v, err := parseVariation(l, pos, format, true)
+			pos.MakeMove(variation.Moves[len(variation.Moves)-1].Move)
+			if err != nil {
+				return variation, err
+			}
+			end, _ := l.next()
+			if len(v.Moves) == 0 {
+				return variation, l.errorf(end.line, end.column, "empty variation")
+			}
+			last := &variation.Moves[len(variation.Moves)-1]
+			last.Variations = append(last.Variations, v)
+		case pgnSymbol:
+			if isPgnResult(token.text) {
+				if nested {
+					return variation, l.errorf(token.line, token.column, "game result inside a variation")
+				}
+				return variation, nil
+			}
+			l.next()
+
+			move, err := ParseMove(token.text, format)
+			if err != nil {
+				return variation, l.errorf(token.line, token.column, "invalid move %q, expected %s", token.text, format.Syntax())
+			}
+			if pos.IsTerminated() {
+				return variation, l.errorf(token.line, token.column, "move %s after the game has ended", token.text)
+			}
+			if !pos.IsLegal(move) {
+				return variation, l.errorf(token.line, token.column, "illegal move %s, possible moves=[%s]", token.text, pos.GenerateMoves().String())
+			}
+			pos.MakeMove(move)
+			played++
+			variation.Moves = append(variation.Moves, GameMove{Move: move})
+		default:
+			return variation, l.errorf(token.line, token.column, "unexpected token in the moves")
+		}
+	}
+}
+
+func joinComments(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + " " + b
+}
+
+// Split the comment into its text and the eval annotation ([%eval 0.55] or [%eval #3])
+func parseComment(text string) (string, *GameEval, error) {
+	start := strings.Index(text, "[%eval ")
+	if start == -1 {
+		return text, nil, nil
+	}
+	end := strings.IndexByte(text[start:], ']')
+	if end == -1 {
+		return "", nil, fmt.Errorf("unterminated eval annotation")
+	}
+
+	value := text[start+len("[%eval ") : start+end]
+	eval := &GameEval{}
+	var err error
+	if mate, ok := strings.CutPrefix(value, "#"); ok {
+		eval.Mate, err = strconv.Atoi(mate)
+		if err == nil && eval.Mate == 0 {
+			err = fmt.Errorf("zero mate distance")
+		}
+	} else {
+		eval.Value, err = strconv.ParseFloat(value, 64)
+		if err == nil && (eval.Value < 0 || eval.Value > 1) {
+			err = fmt.Errorf("expected a value in [0, 1]")
+		}
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid eval annotation %q: %v", value, err)
+	}
+
+	comment := strings.TrimSpace(text[:start] + " " + text[start+end+1:])
+	return comment, eval, nil
+}
@@ -0,0 +1,225 @@
+package uttt
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func moves(t *testing.T, notations ...string) []GameMove {
+	t.Helper()
+	moves := make([]GameMove, len(notations))
+	for i, notation := range notations {
+		if moves[i].Move = MoveFromString(notation); moves[i].Move == PosIllegal {
+			t.Fatalf("Invalid move %s", notation)
+		}
+	}
+	return moves
+}
+
+func TestPgnWrite(t *testing.T) {
+	game := NewGame("")
+	game.SetTag("Cross", "Alice")
+	game.SetTag("Circle", `Bob "the bot"`)
+	game.SetTag("TimeControl", "60+1")
+	game.SetTag(TagResult, ResultCrossWin)
+	game.Comment = "Casual game"
+	game.Moves = moves(t, "B2b2", "B2a1", "A1c3")
+	game.Moves[0].Eval = &GameEval{Value: 0.55}
+	game.Moves[0].Comment = "center"
+	game.Moves[1].Variations = []Variation{
+		{Moves: moves(t, "B2c3", "C3c3")},
+		{Comment: "sharper", Moves: moves(t, "B2a3")},
+	}
+	game.Moves[2].Eval = &GameEval{Mate: -3}
+
+	want := `[Event "?"]
+[Date "????.??.??"]
+[Cross "Alice"]
+[Circle "Bob \"the bot\""]
+[Result "1-0"]
+[TimeControl "60+1"]
+
+{Casual game} 1. B2b2 {[%eval 0.55] center} 1... B2a1 (1... B2c3 2. C3c3)
+({sharper} 1... B2a3) 2. A1c3 {[%eval #-3]} 1-0
+
+`
+	if got := game.String(); got != want {
+		t.Fatalf("Game record:\n%s\nwant:\n%s", got, want)
+	}
+
+	parsed, err := ParseGame(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, game) {
+		t.Errorf("Parsed game=%+v, want=%+v", parsed, game)
+	}
+}
+
+func TestPgnRoundTrip(t *testing.T) {
+	// Circle to move in the starting position
+	circle := NewGame("9/9/9/9/4x4/9/9/9/9 o 4")
+	circle.Moves = moves(t, "B2a1", "A1b2", "B2c3")
+	circle.Moves[1].Variations = []Variation{{Moves: moves(t, "A1a1", "A1b1")}}
+	circle.Moves[2].Comment = "multi word comment"
+
+	// Nested variations, and the long main line
+	nested := NewGame("")
+	nested.SetTag(TagResult, ResultDraw)
+	nested.Moves = moves(t, "B2b2", "B2a1", "A1c3", "C3b2", "B2c1", "C1a1", "A1b2", "B2c2", "C2b3", "B3b2", "B2a2")
+	nested.Moves[2].Variations = []Variation{{Moves: moves(t, "A1a1", "A1b1", "B1c1")}}
+	nested.Moves[2].Variations[0].Moves[1].Variations = []Variation{{Moves: moves(t, "A1c1")}}
+	nested.Moves[4].Eval = &GameEval{Value: 0.125}
+
+	games := []*Game{circle, nested, NewGame("")}
+	builder := strings.Builder{}
+	if err := WriteGames(&builder, games); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ReadGames(strings.NewReader(builder.String()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, builder.String())
+	}
+	if !reflect.DeepEqual(parsed, games) {
+		t.Fatalf("Round trip changed the games:\n%s", builder.String())
+	}
+
+	// Written again, gives the same text
+	again := strings.Builder{}
+	_ = WriteGames(&again, parsed)
+	if again.String() != builder.String() {
+		t.Errorf("Second write:\n%s\nwant:\n%s", again.String(), builder.String())
+	}
+	for _, line := range strings.Split(builder.String(), "\n") {
+		if len(line) > pgnLineLength {
+			t.Errorf("Line longer than %d characters: %q", pgnLineLength, line)
+		}
+	}
+}
+
+func TestPgnNotation(t *testing.T) {
+	game := NewGame("")
+	game.SetTag(TagNotation, "rowcol")
+	game.Moves = moves(t, "B2b2", "B2a1", "A1c3")
+	game.Moves[1].Variations = []Variation{{Moves: moves(t, "B2c3")}}
+
+	want := "1. 4,4 5,3 (1... 3,5) 2. 6,2 *"
+	if got := game.String(); !strings.Contains(got, want) {
+		t.Fatalf("Game record:\n%s\nwant the moves: %s", got, want)
+	}
+	parsed, err := ParseGame(game.String())
+	if err != nil || !reflect.DeepEqual(parsed, game) {
+		t.Fatalf("Parsed game=%+v, err=%v, want=%+v", parsed, err, game)
+	}
+
+	// Without the tag, the moves' format is detected
+	parsed, err = ParseGame("1. 40 B2a1 2. 6,2 *")
+	if err != nil || !reflect.DeepEqual(parsed.Moves, moves(t, "B2b2", "B2a1", "A1c3")) {
+		t.Errorf("Parsed game=%+v, err=%v", parsed, err)
+	}
+
+	game.SetTag(TagNotation, "codingame")
+	if _, err := game.WriteTo(&strings.Builder{}); err == nil {
+		t.Error("Expected an error for the codingame notation")
+	}
+}
+
+func TestReadGames(t *testing.T) {
+	input := `; Games from the tournament
+[Event "First"]
+[Result "0-1"]
+1.B2b2 B2a1 ; line comment
+2.A1c3 0-1
+
+[Event "Second"]
+1. B2b2 {no result tag} *
+`
+	games, err := ReadGames(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("Read %d games, want=2", len(games))
+	}
+	if games[0].Tag("Event") != "First" || len(games[0].Moves) != 3 || games[0].Result() != ResultCircleWin {
+		t.Errorf("First game=%+v", games[0])
+	}
+	if games[1].Result() != ResultUnknown || games[1].Moves[0].Comment != "no result tag" {
+		t.Errorf("Second game=%+v", games[1])
+	}
+}
+
+func TestPgnErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+		msg    string
+	}{
+		{"illegal move", "1. B2b2 C3a1 *", 1, 9, "illegal move C3a1"},
+		{"invalid move", "1. B2b2\n2. X9 *", 2, 4, `invalid move "X9"`},
+		{"after the end", "[Start \"xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1\"]\n1. B3b3 A1a1 1-0", 2, 9, "after the game has ended"},
+		{"illegal variation", "1. B2b2 B2a1 (1... A1a1) *", 1, 20, "illegal move A1a1"},
+		{"unterminated comment", "1. B2b2 {open *", 1, 9, "unterminated comment"},
+		{"unterminated variation", "1. B2b2 (1. A1a1", 1, 17, "unterminated variation"},
+		{"unterminated string", "[Event \"x]\n*", 1, 8, "unterminated string"},
+		{"missing result", "1. B2b2", 1, 8, "expected the game result"},
+		{"result mismatch", "[Result \"1-0\"]\n1. B2b2 0-1", 2, 9, "doesn't match"},
+		{"invalid start", "[Event \"x\"]\n[Start \"9/9 x -\"]\n*", 2, 8, "invalid Start tag"},
+		{"invalid eval", "1. B2b2 {[%eval 1.5]} *", 1, 9, "invalid eval annotation"},
+		{"empty variation", "1. B2b2 () *", 1, 10, "empty variation"},
+		{"variation first", "(1. B2b2) *", 1, 1, "variation before the first move"},
+		{"invalid notation", "[Notation \"codingame\"]\n*", 1, 11, "invalid Notation tag"},
+		{"wrong notation", "[Notation \"index\"]\n1. B2b2 *", 2, 4, `invalid move "B2b2", expected 0-80 index`},
+		{"duplicate tag", "[Event \"a\"]\n[Event \"b\"]\n*", 2, 2, "duplicate tag"},
+		{"unexpected character", "1. B2b2 ! *", 1, 9, "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadGames(strings.NewReader(tt.input))
+			var pgnErr *PgnError
+			if !errors.As(err, &pgnErr) {
+				t.Fatalf("Expected PgnError, got %v", err)
+			}
+			if pgnErr.Line != tt.line || pgnErr.Column != tt.column || !strings.Contains(pgnErr.Msg, tt.msg) {
+				t.Errorf("Error=%v, want line %d, column %d: %s", err, tt.line, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestGameFromPosition(t *testing.T) {
+	const start = "9/9/9/9/4x4/9/9/9/9 o 4"
+	pos, _ := FromNotation(start)
+	for _, move := range []string{"B2a1", "A1b2", "B2c3"} {
+		pos.MakeMove(MoveFromString(move))
+	}
+
+	game := GameFromPosition(pos)
+	if len(game.Moves) != 3 || game.Tag(TagStart) != start || game.Result() != ResultUnknown {
+		t.Fatalf("Game=%v", game)
+	}
+	if pos.stateList.ValidSize() != 3 {
+		t.Errorf("Position's history changed, size=%d", pos.stateList.ValidSize())
+	}
+
+	replayed, err := game.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Notation() != pos.Notation() || !reflect.DeepEqual(replayed.History(), pos.History()) {
+		t.Errorf("Replayed=%s %v, want=%s %v", replayed.Notation(), replayed.History(), pos.Notation(), pos.History())
+	}
+
+	// Finished game has the result
+	mate, _ := FromNotation("xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1")
+	mate.MakeMove(MoveFromString("B3b3"))
+	if result := GameFromPosition(mate).Result(); result != ResultCrossWin {
+		t.Errorf("Result=%s, want=%s", result, ResultCrossWin)
+	}
+}
@@ -45,7 +45,8 @@ func (p *Position) Clone() Position {
 		}
 	}
 
-	copy(pos.stateList.list, p.stateList.list)
+	// Copy the whole history, so the turn and undo information is preserved
+	pos.stateList.list = append(pos.stateList.list[:0], p.stateList.list...)
 	return pos
 }
 
@@ -270,6 +271,15 @@ func (p *Position) UndoMove() {
 	// }
 }
 
+// Moves played since the position was set (with the notation), the oldest first
+func (p *Position) History() []PosType {
+	moves := make([]PosType, 0, p.stateList.ValidSize())
+	for _, state := range p.stateList.list[1:] {
+		moves = append(moves, state.move)
+	}
+	return moves
+}
+
 // Get the 'big position state'
 func (p *Position) BigPositionState() [9]PositionState {
 	return p.bigPositionState
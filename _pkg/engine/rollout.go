@@ -0,0 +1,168 @@
+package uttt
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Rollout policies, choosing the moves played in the MCTS playouts. 'Heavier' policies
+// play more realistic games, at the cost of the rollouts per second
+
+type RolloutPolicy interface {
+	// Choose the move to play, 'moves' are the legal moves in the position (at least one)
+	Choose(pos *Position, moves *MoveList, random *rand.Rand) PosType
+}
+
+// Names of the available policies, see NewRolloutPolicy
+var RolloutPolicyNames = []string{"uniform", "tactical", "nofreechoice", "epsilon"}
+
+// Get the rollout policy by its name
+func NewRolloutPolicy(name string) (RolloutPolicy, error) {
+	switch name {
+	case "uniform":
+		return UniformRollout{}, nil
+	case "tactical":
+		return TacticalRollout{}, nil
+	case "nofreechoice":
+		return NoFreeChoiceRollout{}, nil
+	case "epsilon":
+		return EpsilonGreedyRollout{Epsilon: DefaultRolloutEpsilon}, nil
+	}
+	return nil, fmt.Errorf("Unknown rollout policy %s, expected one of %v", name, RolloutPolicyNames)
+}
+
+// Uniformly random moves (the default)
+type UniformRollout struct{}
+
+func (UniformRollout) Choose(pos *Position, moves *MoveList, random *rand.Rand) PosType {
+	return moves.moves[random.Int31()%int32(moves.size)]
+}
+
+// Wins the small board if possible, else blocks the opponent's win on the small board,
+// otherwise plays a random move
+type TacticalRollout struct{}
+
+func (TacticalRollout) Choose(pos *Position, moves *MoveList, random *rand.Rand) PosType {
+	us, them := _boolToInt(bool(pos.Turn())), _boolToInt(!bool(pos.Turn()))
+	var blocks [9 * 9]PosType
+	nblocks := 0
+
+	for _, move := range moves.Slice() {
+		bi, si := move.BigIndex(), move.SmallIndex()
+		if _completesPattern(pos.bitboards[us][bi], si) {
+			return move
+		}
+		if _completesPattern(pos.bitboards[them][bi], si) {
+			blocks[nblocks] = move
+			nblocks++
+		}
+	}
+
+	if nblocks > 0 {
+		return blocks[random.Intn(nblocks)]
+	}
+	return moves.moves[random.Int31()%int32(moves.size)]
+}
+
+// Random move, but avoids sending the opponent to a resolved board (giving him free choice),
+// if there is no other option, plays a random move
+type NoFreeChoiceRollout struct{}
+
+func (NoFreeChoiceRollout) Choose(pos *Position, moves *MoveList, random *rand.Rand) PosType {
+	var candidates [9 * 9]PosType
+	n := 0
+
+	for _, move := range moves.Slice() {
+		if !pos.sendsToFreeChoice(move) {
+			candidates[n] = move
+			n++
+		}
+	}
+
+	if n > 0 {
+		return candidates[random.Intn(n)]
+	}
+	return moves.moves[random.Int31()%int32(moves.size)]
+}
+
+var DefaultRolloutEpsilon float64 = 0.2
+
+// With 'Epsilon' probability plays a random move, otherwise the best one
+// according to the move heuristic (the same one used by the evaluator's priors)
+type EpsilonGreedyRollout struct {
+	Epsilon float64
+}
+
+func (p EpsilonGreedyRollout) Choose(pos *Position, moves *MoveList, random *rand.Rand) PosType {
+	if random.Float64() < p.Epsilon {
+		return moves.moves[random.Int31()%int32(moves.size)]
+	}
+
+	// Pick the best one, ties are broken at random
+	best, bestScore, ties := moves.moves[0], pos.moveScore(moves.moves[0]), 1
+	for _, move := range moves.Slice()[1:] {
+		score := pos.moveScore(move)
+		switch {
+		case score > bestScore:
+			best, bestScore, ties = move, score, 1
+		case score == bestScore:
+			ties++
+			if random.Intn(ties) == 0 {
+				best = move
+			}
+		}
+	}
+	return best
+}
+
+// Wheter the move sends the opponent to a resolved board, giving him free choice
+func (pos *Position) sendsToFreeChoice(move PosType) bool {
+	bi, si := move.BigIndex(), move.SmallIndex()
+	if si != bi {
+		return pos.bigPositionState[si] != PositionUnResolved
+	}
+
+	// Sending the opponent to the same board, which may get resolved by this move
+	us, them := _boolToInt(bool(pos.Turn())), _boolToInt(!bool(pos.Turn()))
+	our, their := pos.bitboards[us][bi], pos.bitboards[them][bi]
+	return _completesPattern(our, si) || (our|their|(1<<si))&0b111111111 == 0b111111111
+}
+
+// Play 'games' games between the policies, from given position, the policies switch
+// sides after every game. Returns the number of 'a' wins, draws and 'b' wins
+func PlayRolloutMatch(start *Position, a, b RolloutPolicy, games int, random *rand.Rand) (winsA, draws, winsB int) {
+	pos := start.Clone()
+	for game := 0; game < games; game++ {
+		// Side to move in the starting position plays with 'a' in even games
+		aTurn := start.Turn()
+		if game%2 == 1 {
+			aTurn = !aTurn
+		}
+
+		moveCount := 0
+		for !pos.IsTerminated() {
+			moves := pos.GenerateMoves()
+			policy := b
+			if pos.Turn() == aTurn {
+				policy = a
+			}
+			pos.MakeMove(policy.Choose(&pos, moves, random))
+			moveCount++
+		}
+
+		switch t := pos.Termination(); {
+		case t == TerminationDraw:
+			draws++
+		case t == TerminationCrossWon && aTurn == CrossTurn,
+			t == TerminationCircleWon && aTurn == CircleTurn:
+			winsA++
+		default:
+			winsB++
+		}
+
+		for range moveCount {
+			pos.UndoMove()
+		}
+	}
+	return winsA, draws, winsB
+}
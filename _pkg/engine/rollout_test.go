@@ -0,0 +1,100 @@
+package uttt
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestRolloutPoliciesLegal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, name := range RolloutPolicyNames {
+		t.Run(name, func(t *testing.T) {
+			policy, err := NewRolloutPolicy(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for range 50 {
+				pos := NewPosition()
+				for !pos.IsTerminated() {
+					moves := pos.GenerateMoves()
+					move := policy.Choose(pos, moves, random)
+					if !slices.Contains(moves.Slice(), move) {
+						t.Fatalf("Illegal move %s in %s", move.String(), pos.Notation())
+					}
+					pos.MakeMove(move)
+				}
+			}
+		})
+	}
+
+	if _, err := NewRolloutPolicy("unknown"); err == nil {
+		t.Errorf("Expected an error for unknown policy")
+	}
+}
+
+func TestRolloutPolicyRules(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	pos := NewPosition()
+
+	for range 2000 {
+		if pos.IsTerminated() {
+			pos = NewPosition()
+		}
+
+		moves := pos.GenerateMoves()
+		us, them := _boolToInt(bool(pos.Turn())), _boolToInt(!bool(pos.Turn()))
+		canWin, canBlock, canAvoid := false, false, false
+		for _, m := range moves.Slice() {
+			canWin = canWin || _completesPattern(pos.bitboards[us][m.BigIndex()], m.SmallIndex())
+			canBlock = canBlock || _completesPattern(pos.bitboards[them][m.BigIndex()], m.SmallIndex())
+			canAvoid = canAvoid || !pos.sendsToFreeChoice(m)
+		}
+
+		move := TacticalRollout{}.Choose(pos, moves, random)
+		bi, si := move.BigIndex(), move.SmallIndex()
+		if canWin && !_completesPattern(pos.bitboards[us][bi], si) {
+			t.Fatalf("Tactical policy missed the win in %s, played %s", pos.Notation(), move.String())
+		}
+		if !canWin && canBlock && !_completesPattern(pos.bitboards[them][bi], si) {
+			t.Fatalf("Tactical policy didn't block in %s, played %s", pos.Notation(), move.String())
+		}
+
+		move = NoFreeChoiceRollout{}.Choose(pos, moves, random)
+		if canAvoid && pos.sendsToFreeChoice(move) {
+			t.Fatalf("Policy gave free choice in %s, played %s", pos.Notation(), move.String())
+		}
+
+		pos.MakeMove(UniformRollout{}.Choose(pos, moves, random))
+	}
+}
+
+func TestRolloutMatch(t *testing.T) {
+	const games = 400
+	random := rand.New(rand.NewSource(3))
+
+	wins, draws, losses := PlayRolloutMatch(NewPosition(), TacticalRollout{}, UniformRollout{}, games, random)
+	if wins+draws+losses != games {
+		t.Fatalf("Played %d games, want=%d", wins+draws+losses, games)
+	}
+	if wins <= losses {
+		t.Errorf("Tactical policy should beat the uniform one: +%d =%d -%d", wins, draws, losses)
+	}
+}
+
+func benchmarkRolloutPolicy(b *testing.B, policy RolloutPolicy) {
+	ops := newUtttOps(*NewPosition())
+	ops.policy = policy
+	for i := 0; i < b.N; i++ {
+		ops.Rollout()
+	}
+}
+
+func BenchmarkRolloutUniform(b *testing.B)      { benchmarkRolloutPolicy(b, UniformRollout{}) }
+func BenchmarkRolloutTactical(b *testing.B)     { benchmarkRolloutPolicy(b, TacticalRollout{}) }
+func BenchmarkRolloutNoFreeChoice(b *testing.B) { benchmarkRolloutPolicy(b, NoFreeChoiceRollout{}) }
+func BenchmarkRolloutEpsilon(b *testing.B) {
+	benchmarkRolloutPolicy(b, EpsilonGreedyRollout{Epsilon: DefaultRolloutEpsilon})
+}
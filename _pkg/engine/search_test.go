@@ -2,6 +2,7 @@ package uttt
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
 	"testing"
 	"uttt/_pkg/mcts"
@@ -42,10 +43,130 @@ func TestMates(t *testing.T) {
 			if result.Value != mate_depths[i] {
 				t.Error("Expected other winning side, got=", result.Value, "want=", mate_depths[i])
 			}
+
+			// Result of the mate line is exact
+			wantWDL := mcts.WDL{Win: 1}
+			if mate_depths[i] < 0 {
+				wantWDL = mcts.WDL{Loss: 1}
+			}
+			if result.WDL != wantWDL {
+				t.Errorf("WDL=%+v, want=%+v", result.WDL, wantWDL)
+			}
+		})
+	}
+}
+
+func TestSelectionPolicies(t *testing.T) {
+	for _, name := range mcts.SelectionPolicyNames {
+		t.Run(name, func(t *testing.T) {
+			engine := NewEngine()
+			engine.SetLimits(mcts.DefaultLimits().SetCycles(2000).SetSeed(1))
+			cfg := engine.SearchConfig()
+			cfg.Policy = name
+			if err := engine.SetSearchConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			engine.Think()
+			if visits := engine.Mcts().Root.Visits(); visits != 2000 {
+				t.Errorf("Root visits=%d, want=2000", visits)
+			}
+			if move := engine.Mcts().RootSignature(); !engine.Position().IsLegal(move) {
+				t.Errorf("Best move %v is illegal", move)
+			}
 		})
 	}
 }
 
+func TestSolverMateDistance(t *testing.T) {
+	// Mate in 1 for X, and O getting mated in 2 plies (every O's move
+	// allows X to win), the solver should prove the root
+	positions := []string{
+		"xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1",
+		"xxx6/x1x6/xxx6/o3o3o/x1xoxooxo/o3o3o/ooo6/9/9 o 4",
+	}
+	distances := []int{1, 2}
+
+	for i, notation := range positions {
+		t.Run(fmt.Sprintf("Solver-%s", strings.ReplaceAll(notation, "/", "|")), func(t *testing.T) {
+			tree := NewUtttMCTS(*NewPosition())
+			if err := tree.SetNotation(notation); err != nil {
+				t.Fatal(err)
+			}
+
+			tree.Limits().SetCycles(100000)
+			tree.Search()
+
+			if !tree.Root.Proven() {
+				t.Fatal("Root should be proven")
+			}
+
+			// The search should stop, as soon as the root is proven
+			if tree.Root.Visits() >= 100000 {
+				t.Errorf("Search should stop early, visits=%d", tree.Root.Visits())
+			}
+
+			best := tree.BestChild(tree.Root, mcts.BestChildMostVisits)
+			if d := best.ProofDistance(); d != distances[i] {
+				t.Errorf("ProofDistance=%d, want=%d", d, distances[i])
+			}
+
+			// Losing side picks the longest line, winning - the shortest
+			if distances[i]%2 == 1 && !best.ProvenWin() {
+				t.Error("Best child should be a proven win")
+			} else if distances[i]%2 == 0 && !best.ProvenLoss() {
+				t.Error("Best child should be a proven loss")
+			}
+
+			result, _ := tree.SearchResult(mcts.BestChildMostVisits).MainLine()
+			if result.ScoreType != MateScore || abs(result.Value) != distances[i] {
+				t.Errorf("Got result=%v, want mate in %d", result, distances[i])
+			}
+		})
+	}
+}
+
+func TestSolverDisabled(t *testing.T) {
+	tree := NewUtttMCTS(*NewPosition())
+	tree.SetSolver(false)
+	if err := tree.SetNotation("xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tree.Limits().SetCycles(1000)
+	tree.Search()
+
+	if tree.Root.Proven() {
+		t.Error("Root shouldn't be proven with solver disabled")
+	}
+	if tree.Root.Visits() < 1000 {
+		t.Errorf("Search should use all the cycles, visits=%d", tree.Root.Visits())
+	}
+}
+
+func TestSearchMatch(t *testing.T) {
+	const games = 4
+	halving, plain := NewEngine(), NewEngine()
+	halving.SetLimits(mcts.DefaultLimits().SetCycles(200).SetRootPolicy(mcts.RootSequentialHalving))
+	plain.SetLimits(mcts.DefaultLimits().SetCycles(200))
+
+	random := rand.New(rand.NewSource(5))
+	wins, draws, losses := PlaySearchMatch(NewPosition(), halving, plain, games, 2, random)
+	if wins+draws+losses != games {
+		t.Fatalf("Played %d games, want=%d", wins+draws+losses, games)
+	}
+	if halving.Mcts().Root.Visits() > 200 {
+		t.Errorf("Search used %d cycles, limit=200", halving.Mcts().Root.Visits())
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
 func BenchmarkSingleThreadedSearch(b *testing.B) {
 	engine := NewEngine()
 	engine.SetLimits(mcts.DefaultLimits().SetThreads(1).SetCycles(10000))
@@ -0,0 +1,53 @@
+package uttt
+
+import (
+	"sync"
+	"uttt/_pkg/mcts"
+)
+
+// Node index used by the DAG mode of the search (see mcts.TranspositionIndex),
+// maps the position's hash to the first node expanded with that position
+
+const DefaultNodeIndexSize uint64 = 1 << 18
+
+type _nodeIndexEntry struct {
+	HashEntryBase
+	node *mcts.NodeBase[PosType]
+}
+
+type NodeIndex struct {
+	mu    sync.Mutex
+	table *HashTable[_nodeIndexEntry]
+}
+
+func NewNodeIndex(size uint64) *NodeIndex {
+	return &NodeIndex{table: NewHashTable[_nodeIndexEntry](size)}
+}
+
+// Get the node stored with given hash, if there is none, store the 'node' and return nil.
+// On the index collision the entry is replaced
+func (index *NodeIndex) LoadOrStore(hash uint64, node *mcts.NodeBase[PosType]) *mcts.NodeBase[PosType] {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if entry, ok := index.table.Get(hash); ok {
+		return entry.node
+	}
+
+	index.table.SetForced(hash, _nodeIndexEntry{HashEntryBase: HashEntryBase{Hash: hash}, node: node})
+	return nil
+}
+
+func (index *NodeIndex) Clear() {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.table.Clear()
+}
+
+// Find the node with the same position (see mcts.TranspositionIndex)
+func (ops *UtttOperations) Transposition(node *mcts.NodeBase[PosType]) *mcts.NodeBase[PosType] {
+	if ops.index == nil {
+		return nil
+	}
+	return ops.index.LoadOrStore(ops.position.Hash(), node)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"unsafe"
+	"uttt/_pkg/mcts"
 )
 
 // Type defines for the position
@@ -27,6 +28,7 @@ type EngineLine struct {
 	Bestmove  PosType `json:"bestmove"`
 	Value     int     `json:"eval"`
 	ScoreType ScoreType
+	WDL       mcts.WDL `json:"wdl"` // from the perspective of the side to move
 	Pv        []PosType
 }
 
@@ -63,8 +65,10 @@ type SearchResult struct {
 
 func (s SearchResult) String() string {
 	if len(s.Lines) > 0 {
-		return fmt.Sprintf("eval %s depth %d cps %d nodes %d cycles %d pv %v",
-			s.Lines[0].StringValue(s.Turn, false), s.Depth, s.Cps, s.Nodes, s.Cycles, s.Lines[0].Pv)
+		wdl := s.Lines[0].WDL
+		return fmt.Sprintf("eval %s wdl %d %d %d depth %d cps %d nodes %d cycles %d pv %v",
+			s.Lines[0].StringValue(s.Turn, false), int(1000*wdl.Win), int(1000*wdl.Draw), int(1000*wdl.Loss),
+			s.Depth, s.Cps, s.Nodes, s.Cycles, s.Lines[0].Pv)
 	}
 
 	return fmt.Sprintf("eval NaN depth %d cps %d nodes %d cycles %d pv empty",
@@ -1,6 +1,9 @@
 package uttt
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"math/rand"
 	"time"
 	"uttt/_pkg/mcts"
@@ -25,26 +28,39 @@ func NewUtttMCTS(position Position) *UtttMCTS {
 		),
 		ops: uttt_ops,
 	}
+
+	// Back up the proven results by default
+	tree.SetSolver(true)
 	return tree
 }
 
 func (mcts *UtttMCTS) AsyncSearch() {
+	mcts.Limits().SetSide(ClockSide(mcts.ops.position.Turn()))
 	mcts.MCTS.SearchMultiThreaded(mcts.ops)
 }
 
 // Start the search
 func (mcts *UtttMCTS) Search() {
+	mcts.SearchContext(context.Background())
+}
 
-	// Run the search
-	mcts.SearchMultiThreaded(mcts.ops)
+// Search until the limits are reached or the context is cancelled, blocks until it ends
+func (mcts *UtttMCTS) SearchContext(ctx context.Context) {
+	mcts.Limits().SetSide(ClockSide(mcts.ops.position.Turn()))
+	mcts.MCTS.SearchContext(ctx, mcts.ops)
+}
 
-	// Wait for the search to end
-	mcts.Synchronize()
+// Index of the side's clock in mcts.Limits, cross uses the first one (like white in chess)
+func ClockSide(turn TurnType) int {
+	if turn == CrossTurn {
+		return 0
+	}
+	return 1
 }
 
 // Default selection
 func (mcts *UtttMCTS) Selection() *mcts.NodeBase[PosType] {
-	return mcts.MCTS.Selection(mcts.ops, rand.New(rand.NewSource(time.Now().UnixNano())), 0)
+	return mcts.MCTS.Selection(mcts.ops, mcts.NewRand(0), 0)
 }
 
 // Default backprop
@@ -66,6 +82,72 @@ func (mcts *UtttMCTS) SetPosition(position Position) {
 	mcts.Reset()
 }
 
+// Make given moves on the position, and reuse the matching subtree as the new root,
+// so the accumulated statistics aren't lost. If there is no such subtree, resets the tree.
+// Moves should be legal
+func (mcts *UtttMCTS) MakeMove(moves ...PosType) {
+	for _, move := range moves {
+		mcts.ops.position.MakeMove(move)
+	}
+
+	if !mcts.AdvanceRoot(mcts.ops, moves...) {
+		mcts.Reset()
+	}
+}
+
+// Save the search tree, with the root position in the header (see mcts.WriteTree)
+func (mcts *UtttMCTS) WriteTo(w io.Writer) (int64, error) {
+	return mcts.WriteTree(w, mcts.ops.position.Notation())
+}
+
+// Load the tree saved with WriteTo, sets the position to the tree's root position,
+// so the search can be resumed
+func (tree *UtttMCTS) LoadTree(r io.Reader) error {
+	root, header, err := mcts.ReadTree[PosType](r)
+	if err != nil {
+		return err
+	}
+
+	pos, err := FromNotation(header.Notation)
+	if err != nil {
+		return fmt.Errorf("Invalid root position in the tree: %w", err)
+	}
+
+	tree.ops.position = *pos
+	tree.SetRoot(tree.ops, root)
+	return nil
+}
+
+// Enable or disable the node arena (see mcts.NodeArena), resets the tree
+func (tree *UtttMCTS) SetArena(enabled bool) {
+	if enabled {
+		tree.MCTS.SetArena(mcts.NewNodeArena[PosType](mcts.DefaultArenaChunkSize))
+	} else {
+		tree.MCTS.SetArena(nil)
+	}
+	tree.Reset()
+}
+
+// Enable or disable the DAG mode, where the transpositions share their subtrees
+// (see mcts.SetDAG), resets the tree
+func (mcts *UtttMCTS) SetTranspositions(enabled bool) {
+	if enabled {
+		// Position hashes are required
+		Init()
+		mcts.ops.index = NewNodeIndex(DefaultNodeIndexSize)
+	} else {
+		mcts.ops.index = nil
+	}
+	mcts.SetDAG(enabled)
+	mcts.Reset()
+}
+
+// Set the policy choosing the moves in the rollouts, the policy is shared
+// between the search threads, so it shouldn't have any mutable state
+func (mcts *UtttMCTS) SetRolloutPolicy(policy RolloutPolicy) {
+	mcts.ops.policy = policy
+}
+
 func (mcts *UtttMCTS) SetNotation(notation string) error {
 	defer mcts.Reset()
 	return mcts.ops.position.FromNotation(notation)
@@ -86,6 +168,8 @@ func ToSearchResult(stats mcts.ListenerTreeStats[PosType], turn TurnType) Search
 		treeLine := &stats.Lines[i]
 		line := &result.Lines[i]
 		line.Pv = treeLine.Moves
+		line.Bestmove = treeLine.BestMove
+		line.WDL = treeLine.WDL
 
 		// Set the score
 		if treeLine.Terminal {
@@ -94,7 +178,7 @@ func ToSearchResult(stats mcts.ListenerTreeStats[PosType], turn TurnType) Search
 				line.Value = 50
 			} else {
 				line.ScoreType = MateScore
-				line.Value = len(treeLine.Moves)
+				line.Value = treeLine.MateDistance
 
 				// If the game ends on our turn, we are losing
 				if line.Value%2 == 0 {
@@ -126,6 +210,8 @@ func (mcts *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) SearchResult {
 		pvResult := multipv[i]
 		line := &result.Lines[i]
 		line.Pv = pvResult.Pv
+		line.Bestmove = pvResult.Root.NodeSignature
+		line.WDL = pvResult.WDL()
 
 		// Set the score
 		if pvResult.Terminal {
@@ -134,7 +220,7 @@ func (mcts *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) SearchResult {
 				line.Value = 50
 			} else {
 				line.ScoreType = MateScore
-				line.Value = len(pvResult.Pv)
+				line.Value = pvResult.MateDistance
 
 				// If the game ends on our turn, we are losing
 				if line.Value%2 == 0 {
@@ -150,9 +236,12 @@ func (mcts *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) SearchResult {
 }
 
 type UtttOperations struct {
-	position Position
-	rootSide TurnType
-	random   *rand.Rand
+	position     Position
+	rootSide     TurnType
+	random       *rand.Rand
+	rolloutMoves []PosType // moves played in the last rollout, used by the RAVE
+	policy       RolloutPolicy
+	index        *NodeIndex // shared between the clones, used in the DAG mode
 }
 
 func newUtttOps(pos Position) *UtttOperations {
@@ -160,17 +249,30 @@ func newUtttOps(pos Position) *UtttOperations {
 		position: pos,
 		rootSide: pos.Turn(),
 		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		policy:   UniformRollout{},
 	}
 }
 
 func (ops *UtttOperations) Reset() {
 	ops.rootSide = ops.position.Turn()
+	if ops.index != nil {
+		ops.index.Clear()
+	}
 }
 
 func (ops *UtttOperations) ExpandNode(node *mcts.NodeBase[PosType]) uint32 {
+	return ops.ExpandNodeIn(node, _makeChildren)
+}
+
+func _makeChildren(n int) []mcts.NodeBase[PosType] {
+	return make([]mcts.NodeBase[PosType], n)
+}
+
+// Expand the node, allocating the children with 'alloc' (see mcts.ArenaExpander)
+func (ops *UtttOperations) ExpandNodeIn(node *mcts.NodeBase[PosType], alloc func(int) []mcts.NodeBase[PosType]) uint32 {
 
 	moves := ops.position.GenerateMoves()
-	node.Children = make([]mcts.NodeBase[PosType], moves.size)
+	node.Children = alloc(int(moves.size))
 
 	for i, m := range moves.Slice() {
 		ops.position.MakeMove(m)
@@ -198,14 +300,20 @@ func (ops *UtttOperations) Rollout() mcts.Result {
 	var result mcts.Result = 0.5
 	var moveCount int = 0
 	leafTurn := ops.position.Turn()
+	ops.rolloutMoves = ops.rolloutMoves[:0]
+
+	policy := ops.policy
+	if policy == nil {
+		policy = UniformRollout{}
+	}
 
 	for !ops.position.IsTerminated() {
 		moveCount++
 		moves = ops.position.GenerateMoves()
 
-		// Choose at random move
-		move = moves.moves[ops.random.Int31()%int32(moves.size)]
+		move = policy.Choose(&ops.position, moves, ops.random)
 		ops.position.MakeMove(move)
+		ops.rolloutMoves = append(ops.rolloutMoves, move)
 	}
 
 	// If that's not a draw
@@ -224,10 +332,22 @@ func (ops *UtttOperations) Rollout() mcts.Result {
 	return result
 }
 
+// Moves played in the last rollout (see mcts.RolloutRecorder)
+func (ops *UtttOperations) RolloutMoves() []PosType {
+	return ops.rolloutMoves
+}
+
+// Reseed the rollouts' random number generator (see mcts.Seeder)
+func (ops *UtttOperations) Seed(seed int64) {
+	ops.random.Seed(seed)
+}
+
 func (ops UtttOperations) Clone() mcts.GameOperations[PosType] {
 	return mcts.GameOperations[PosType](&UtttOperations{
 		position: ops.position.Clone(),
 		rootSide: ops.rootSide,
 		random:   rand.New(rand.NewSource(time.Now().UnixMicro())),
+		policy:   ops.policy,
+		index:    ops.index,
 	})
 }
@@ -1,12 +1,23 @@
 package uttt
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/rand"
+	"reflect"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unsafe"
 	"uttt/_pkg/mcts"
+	"uttt/_pkg/mcts/mctstest"
 )
 
 func TestMCTSBasicFunctionality(t *testing.T) {
@@ -170,7 +181,8 @@ func TestMCTSBackpropagation(t *testing.T) {
 	tree.Root.SetVvl(1, 0)
 
 	child := &tree.Root.Children[0]
-	child.SetVvl(int32(mcts.VirtualLoss), int32(mcts.VirtualLoss))
+	virtualLoss := tree.Config().VirtualLoss
+	child.SetVvl(virtualLoss, virtualLoss)
 
 	// Test backpropagation with win
 	originalNotation := pos.Notation()
@@ -269,15 +281,20 @@ func TestMCTSUCB1Calculation(t *testing.T) {
 	}
 
 	children[0].SetVvl(10, 0)
-	children[0].AddOutcome(7.0)
+	for range 7 {
+		children[0].AddOutcome(1)
+	}
 	children[1].SetVvl(8, 0)
-	children[1].AddOutcome(3.0)
+	for range 3 {
+		children[1].AddOutcome(1)
+	}
 	children[2].SetVvl(0, 0)
 
 	parent.Children = children
 
 	// Test selection policy
-	selected := mcts.UCB1(parent, parent)
+	cfg := mcts.DefaultSearchConfig()
+	selected := mcts.UCB1(parent, parent, &cfg)
 
 	// Should select unvisited node
 	if selected.Visits() != 0 {
@@ -286,7 +303,7 @@ func TestMCTSUCB1Calculation(t *testing.T) {
 
 	// Remove unvisited node and test UCB1
 	parent.Children = children[:2]
-	selected = mcts.UCB1(parent, parent)
+	selected = mcts.UCB1(parent, parent, &cfg)
 
 	// Verify UCB1 calculation makes sense
 	if selected == nil {
@@ -298,7 +315,7 @@ func TestMCTSUCB1Calculation(t *testing.T) {
 		node := &parent.Children[i]
 		if node.Visits() > 0 {
 			winRate := float64(node.Outcomes()) / float64(node.Visits())
-			exploration := mcts.ExplorationParam * math.Sqrt(math.Log(float64(parent.Visits()))/float64(node.Visits()))
+			exploration := cfg.Exploration * math.Sqrt(math.Log(float64(parent.Visits()))/float64(node.Visits()))
 			ucb1 := winRate + exploration
 
 			if math.IsNaN(ucb1) || math.IsInf(ucb1, 0) {
@@ -355,6 +372,445 @@ func TestMCTSMultiThreadedSearch(t *testing.T) {
 	t.Log(result)
 }
 
+func TestMCTSAdvanceRoot(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	tree.Limits().SetCycles(5000)
+	tree.Search()
+
+	// Play the best move and its best reply, and keep the subtree
+	child := tree.BestChild(tree.Root, mcts.BestChildMostVisits)
+	reply := tree.BestChild(child, mcts.BestChildMostVisits)
+	if child == nil || reply == nil {
+		t.Fatal("Expected the search to expand at least 2 plies")
+	}
+
+	visits := reply.RealVisits()
+	tree.MakeMove(child.NodeSignature, reply.NodeSignature)
+
+	if tree.Root.Visits() != visits {
+		t.Errorf("Root visits=%d, want=%d", tree.Root.Visits(), visits)
+	}
+	if tree.Root.Parent != nil {
+		t.Error("New root should be detached from its parent")
+	}
+	if int(tree.Size()) != tree.Count() {
+		t.Errorf("Size=%d, want=%d", tree.Size(), tree.Count())
+	}
+	for i := range tree.Root.Children {
+		if tree.Root.Children[i].Parent != tree.Root {
+			t.Fatalf("Child %d parent pointer incorrect", i)
+		}
+	}
+
+	// Next search should continue from the accumulated statistics
+	tree.Limits().SetCycles(uint32(visits) + 1000)
+	tree.Search()
+
+	if tree.Root.Visits() < visits+1000 {
+		t.Errorf("Root visits=%d, want at least %d", tree.Root.Visits(), visits+1000)
+	}
+
+	if tree.ops.rootSide != tree.ops.position.Turn() {
+		t.Error("Root side should match the side to move")
+	}
+}
+
+func TestMCTSSearchAfterMove(t *testing.T) {
+	engine := NewEngine()
+	for _, move := range []string{"B2b2", "B2a1", "A1c3"} {
+		if err := engine.MakeMove(MoveFromString(move)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notation, turn := engine.Position().Notation(), engine.Position().Turn()
+
+	// Every cycle should leave the thread's position (with its history) as it was
+	tree := engine.mcts
+	ops := tree.ops.Clone().(*UtttOperations)
+	random := rand.New(rand.NewSource(0))
+	for i := range 1000 {
+		node := tree.MCTS.Selection(ops, random, 0)
+		tree.MCTS.Backpropagate(ops, node, ops.Rollout())
+
+		pos := &ops.position
+		if pos.Notation() != notation || pos.Turn() != turn || pos.stateList.ValidSize() != 3 {
+			t.Fatalf("Cycle %d changed the position to %s (turn=%v, history=%d), want=%s (turn=%v, history=3)",
+				i, pos.Notation(), pos.Turn(), pos.stateList.ValidSize(), notation, turn)
+		}
+	}
+
+	engine.SetLimits(mcts.DefaultLimits().SetCycles(2000).SetThreads(2))
+	engine.Think()
+	if pos := engine.Position(); pos.Notation() != notation || pos.Turn() != turn || pos.stateList.ValidSize() != 3 {
+		t.Errorf("Search changed the engine's position to %s (history=%d), want=%s", pos.Notation(), pos.stateList.ValidSize(), notation)
+	}
+}
+
+func TestMCTSAdvanceRootMissingPath(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	moves := tree.ops.position.GenerateMoves().Slice()
+	tree.ops.position.MakeMove(moves[0])
+
+	// Only the root's children are in the tree
+	reply := tree.ops.position.GenerateMoves().Slice()[0]
+	if tree.AdvanceRoot(tree.ops, moves[0], reply) {
+		t.Error("AdvanceRoot should fail, when the path isn't in the tree")
+	}
+	tree.ops.position.UndoMove()
+
+	// The moves are still played, the tree is reset
+	tree.MakeMove(moves[0], reply)
+	if tree.Root.Visits() != 0 || len(tree.Root.Children) == 0 {
+		t.Errorf("Expected a fresh, expanded root, visits=%d children=%d",
+			tree.Root.Visits(), len(tree.Root.Children))
+	}
+}
+
+func TestMCTSRaveSearch(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	tree.SetRave(true)
+	tree.SetSelectionPolicy(mcts.UCB1RAVE)
+	tree.Limits().SetThreads(2).SetCycles(5000)
+	tree.Search()
+
+	// Every simulation updates the AMAF statistics of the root's children
+	// several times, so there should be more of them than the regular visits
+	amafVisits := int32(0)
+	for i := range tree.Root.Children {
+		amafVisits += tree.Root.Children[i].AmafVisits()
+	}
+	if amafVisits <= tree.Root.Visits() {
+		t.Errorf("AMAF visits=%d, should be greater than root visits=%d", amafVisits, tree.Root.Visits())
+	}
+
+	if !pos.IsLegal(tree.RootSignature()) {
+		t.Errorf("Best move %v is illegal", tree.RootSignature())
+	}
+}
+
+func TestMCTSRootParallelSearch(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	tree.Limits().SetThreads(4).SetCycles(20000).SetParallel(mcts.RootParallel)
+	tree.Search()
+
+	// Private trees should be merged into the main one
+	visits := tree.Root.Visits()
+	if visits < 20000 {
+		t.Errorf("Root visits=%d, want at least %d", visits, 20000)
+	}
+
+	childVisits := int32(0)
+	for i := range tree.Root.Children {
+		childVisits += tree.Root.Children[i].RealVisits()
+	}
+	if childVisits != visits {
+		t.Errorf("Sum of the children visits=%d, want=%d", childVisits, visits)
+	}
+	if int(tree.Size()) != tree.Count() {
+		t.Errorf("Size=%d, should count only the main tree=%d", tree.Size(), tree.Count())
+	}
+
+	result, _ := tree.SearchResult(mcts.BestChildMostVisits).MainLine()
+	if !pos.IsLegal(result.Bestmove) || len(result.Pv) == 0 {
+		t.Errorf("Invalid result %v", result)
+	}
+}
+
+func TestMCTSSaveLoadTree(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos.MakeMove(MoveFromString("B2b2"))
+
+	tree := NewUtttMCTS(*pos)
+	tree.Limits().SetThreads(2).SetCycles(5000)
+	tree.Search()
+
+	buffer := bytes.Buffer{}
+	if _, err := tree.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewUtttMCTS(*NewPosition())
+	if err := loaded.LoadTree(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.ops.position.Notation() != pos.Notation() {
+		t.Errorf("Position=%s, want=%s", loaded.ops.position.Notation(), pos.Notation())
+	}
+	if loaded.Size() != uint32(tree.Count()) || loaded.Root.Visits() != tree.Root.Visits() {
+		t.Errorf("Loaded size=%d visits=%d, want size=%d visits=%d",
+			loaded.Size(), loaded.Root.Visits(), tree.Count(), tree.Root.Visits())
+	}
+	if loaded.RootSignature() != tree.RootSignature() {
+		t.Errorf("Best move=%v, want=%v", loaded.RootSignature(), tree.RootSignature())
+	}
+
+	// Resume the search
+	visits := loaded.Root.Visits()
+	loaded.Limits().SetThreads(2).SetCycles(uint32(visits) + 1000)
+	loaded.Search()
+	if loaded.Root.Visits() <= visits {
+		t.Errorf("Search wasn't resumed, visits=%d, before=%d", loaded.Root.Visits(), visits)
+	}
+	if !loaded.ops.position.IsLegal(loaded.RootSignature()) {
+		t.Errorf("Illegal best move %v", loaded.RootSignature())
+	}
+}
+
+func TestMCTSLoadCorruptedTree(t *testing.T) {
+	// Saved tree of the root with given flags, and the children (leaves) with their flags
+	save := func(rootFlags uint32, childFlags ...uint32) *bytes.Buffer {
+		buffer := &bytes.Buffer{}
+		write := func(data any) {
+			if err := binary.Write(buffer, binary.LittleEndian, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write([]byte("MCTS"))
+		write(mcts.TreeFormatVersion)
+		write(uint16(binary.Size(PosType(0))))
+		write(uint16(len(StartingPosition)))
+		write([]byte(StartingPosition))
+		write(uint32(1 + len(childFlags)))
+
+		node := func(move PosType, flags uint32, children int) {
+			write(move)
+			write(int32(10))
+			write([3]uint64{5000, 0, 5000})
+			write(flags)
+			write(uint32(children))
+		}
+		node(0, rootFlags, len(childFlags))
+		moves := NewPosition().GenerateMoves().Slice()
+		for i, flags := range childFlags {
+			node(moves[i], flags, 0)
+		}
+		return buffer
+	}
+
+	tests := []struct {
+		name  string
+		data  *bytes.Buffer
+		valid bool
+	}{
+		{"expanded without children", save(mcts.ExpandedMask), false},
+		{"terminal with children", save(mcts.ExpandedMask|mcts.TerminalMask, 0), false},
+		{"children without expanded flag", save(0, 0, 0), false},
+		{"leaf marked as expanded", save(mcts.ExpandedMask, mcts.ExpandedMask), false},
+		{"expanding flags", save(mcts.ExpandedMask|mcts.ExpandingMask, mcts.ExpandingMask, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := NewUtttMCTS(*NewPosition())
+			err := tree.LoadTree(tt.data)
+			if (err == nil) != tt.valid {
+				t.Fatalf("err=%v, valid=%v", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+
+			// Expanding flag is cleared, so the nodes can be expanded by the search
+			tree.Limits().SetThreads(2).SetCycles(2000)
+			tree.Search()
+			for i := range tree.Root.Children {
+				if child := &tree.Root.Children[i]; child.Expanding() {
+					t.Errorf("Child %v is still being expanded", child.NodeSignature)
+				}
+			}
+			if !tree.ops.position.IsLegal(tree.RootSignature()) {
+				t.Errorf("Illegal best move %v", tree.RootSignature())
+			}
+		})
+	}
+}
+
+func TestMCTSArenaSearch(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Memory limit is much smaller, than the tree would be
+	tree := NewUtttMCTS(*pos)
+	tree.SetArena(true)
+	tree.Limits().SetThreads(4).SetCycles(20000).SetMbSize(1)
+	tree.Search()
+
+	maxSize := uint32((1 << 20) / unsafe.Sizeof(UtttNode{}))
+	if tree.Size() > maxSize {
+		t.Errorf("Size=%d, exceeds the limit %d", tree.Size(), maxSize)
+	}
+	if int(tree.Size()) != tree.Count() {
+		t.Errorf("Size=%d, want=%d", tree.Size(), tree.Count())
+	}
+	if tree.Root.Visits() < 20000 {
+		t.Errorf("Search should keep going after pruning, visits=%d", tree.Root.Visits())
+	}
+
+	// Discarded part of the tree is recycled
+	child := tree.BestChild(tree.Root, mcts.BestChildMostVisits)
+	tree.MakeMove(child.NodeSignature)
+	if int(tree.Size()) != tree.Count() {
+		t.Errorf("After the move: size=%d, want=%d", tree.Size(), tree.Count())
+	}
+
+	tree.Limits().SetThreads(4).SetCycles(uint32(tree.Root.Visits()) + 5000).SetMbSize(1)
+	tree.Search()
+	if !tree.ops.position.IsLegal(tree.RootSignature()) {
+		t.Errorf("Illegal best move %v", tree.RootSignature())
+	}
+}
+
+func TestMCTSArenaListener(t *testing.T) {
+	// The listener reads the tree, while it's being pruned (run with -race)
+	for _, parallel := range []mcts.ParallelMode{mcts.TreeParallel, mcts.RootParallel} {
+		t.Run(fmt.Sprintf("parallel=%d", parallel), func(t *testing.T) {
+			tree := NewUtttMCTS(*NewPosition())
+			tree.SetArena(true)
+			tree.Limits().SetThreads(4).SetCycles(20000).SetMbSize(1).SetParallel(parallel)
+
+			var calls atomic.Int32
+			tree.StatsListener().OnCycle(func(s mcts.ListenerTreeStats[PosType]) {
+				if len(s.Lines) > 0 && len(s.Lines[0].Moves) > 0 {
+					calls.Add(1)
+				}
+			})
+			tree.Search()
+
+			if calls.Load() == 0 {
+				t.Error("Listener should see the principal variation")
+			}
+			if int(tree.Size()) != tree.Count() {
+				t.Errorf("Size=%d, want=%d", tree.Size(), tree.Count())
+			}
+		})
+	}
+}
+
+func TestMCTSTranspositions(t *testing.T) {
+	// Opening transpositions are rare, since the moves are forced to the same boards
+	pos, err := FromNotation("8o/9/x8/9/6x2/9/2o6/9/x8 o 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	tree.SetTranspositions(true)
+	tree.Limits().SetThreads(4).SetCycles(40000)
+
+	var stats mcts.ListenerTreeStats[PosType]
+	tree.StatsListener().OnStop(func(s mcts.ListenerTreeStats[PosType]) { stats = s })
+	tree.Search()
+
+	if stats.Transpositions == 0 || stats.Transpositions != int(tree.Transpositions()) {
+		t.Errorf("Reported transpositions=%d, tree has=%d, expected some", stats.Transpositions, tree.Transpositions())
+	}
+
+	// Shared subtrees are counted once, the same as while expanding
+	if count := tree.Count(); count != int(tree.Size()) {
+		t.Errorf("Count=%d, size=%d", count, tree.Size())
+	}
+
+	// Every cycle goes through exactly one of the root's children
+	childVisits := int32(0)
+	for i := range tree.Root.Children {
+		childVisits += tree.Root.Children[i].RealVisits()
+	}
+	if childVisits != tree.Root.Visits() {
+		t.Errorf("Sum of the children visits=%d, root visits=%d", childVisits, tree.Root.Visits())
+	}
+
+	result, _ := tree.SearchResult(mcts.BestChildMostVisits).MainLine()
+	if !pos.IsLegal(result.Bestmove) {
+		t.Errorf("Illegal best move %v", result.Bestmove)
+	}
+
+	// Pv should be a legal line, even through the shared subtrees
+	for _, move := range result.Pv {
+		if !pos.IsLegal(move) {
+			t.Fatalf("Illegal pv move %v in %v", move, result.Pv)
+		}
+		pos.MakeMove(move)
+	}
+}
+
+// Search from the starting position, with the infinite-analysis-like memory limit,
+// reports the cycles per second and the GC pause time per search
+func benchmarkNodeAllocator(b *testing.B, arena bool) {
+	pos, _ := FromNotation(StartingPosition)
+	tree := NewUtttMCTS(*pos)
+	tree.SetArena(arena)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	cps := 0.0
+
+	for i := 0; i < b.N; i++ {
+		tree.Reset()
+		tree.Limits().SetThreads(4).SetCycles(50000).SetMbSize(4)
+		tree.Search()
+		cps += float64(tree.Cps())
+	}
+
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(cps/float64(b.N), "cycles/s")
+	b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+}
+
+func BenchmarkMCTSHeapNodes(b *testing.B) {
+	benchmarkNodeAllocator(b, false)
+}
+
+func BenchmarkMCTSArenaNodes(b *testing.B) {
+	benchmarkNodeAllocator(b, true)
+}
+
+func benchmarkParallelSearch(b *testing.B, mode mcts.ParallelMode) {
+	pos, _ := FromNotation(StartingPosition)
+	tree := NewUtttMCTS(*pos)
+
+	for i := 0; i < b.N; i++ {
+		tree.Reset()
+		tree.Limits().SetThreads(4).SetCycles(20000).SetParallel(mode)
+		tree.Search()
+	}
+	b.ReportMetric(float64(tree.Cps()), "cycles/s")
+}
+
+func BenchmarkMCTSTreeParallel(b *testing.B) {
+	benchmarkParallelSearch(b, mcts.TreeParallel)
+}
+
+func BenchmarkMCTSRootParallel(b *testing.B) {
+	benchmarkParallelSearch(b, mcts.RootParallel)
+}
+
 func BenchmarkMCTSRollout(b *testing.B) {
 	pos := NewPosition()
 	err := pos.FromNotation(StartingPosition)
@@ -369,3 +825,335 @@ func BenchmarkMCTSRollout(b *testing.B) {
 		ops.Rollout()
 	}
 }
+
+func TestMCTSClockSearch(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the side to move's clock is used
+	tree := NewUtttMCTS(*pos)
+	tree.Limits().SetThreads(2).SetClock(ClockSide(pos.Turn()), 2000, 0)
+	tree.Search()
+
+	cfg := tree.Config()
+	tm := mcts.NewTimeManager[PosType](tree.Limits(), &cfg)
+	if elapsed := tree.Limiter.Elapsed(); elapsed > tm.Hard()+50 {
+		t.Errorf("Elapsed=%dms, exceeds the hard deadline %dms", elapsed, tm.Hard())
+	}
+
+	best := tree.BestChild(tree.Root, mcts.BestChildMostVisits)
+	if best == nil || !slices.Contains(pos.GenerateMoves().Slice(), best.NodeSignature) {
+		t.Errorf("Invalid best move %v", best)
+	}
+}
+
+func TestMCTSSearchContext(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modes := []struct {
+		name string
+		mode mcts.ParallelMode
+	}{
+		{"tree parallel", mcts.TreeParallel},
+		{"root parallel", mcts.RootParallel},
+	}
+
+	for _, m := range modes {
+		t.Run(m.name, func(t *testing.T) {
+			// Infinite search, ends only with the cancellation
+			tree := NewUtttMCTS(*pos)
+			tree.Limits().SetThreads(4).SetParallel(m.mode)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			tree.SearchContext(ctx)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Search took %v after cancelling", elapsed)
+			}
+			if tree.IsThinking() {
+				t.Error("Search should be stopped")
+			}
+
+			// Partial result is kept
+			best := tree.BestChild(tree.Root, mcts.BestChildMostVisits)
+			if tree.Root.Visits() == 0 || best == nil || best.Visits() == 0 {
+				t.Errorf("No partial result, root visits=%d", tree.Root.Visits())
+			}
+
+			// Cancelled context doesn't affect the next search
+			tree.Reset()
+			tree.Limits().SetThreads(4).SetCycles(5000)
+			tree.Search()
+			if tree.Root.Visits() < 5000 {
+				t.Errorf("Next search was stopped, visits=%d", tree.Root.Visits())
+			}
+		})
+	}
+
+	// Already cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	engine := NewEngine()
+	engine.SetLimits(mcts.DefaultLimits().SetThreads(2))
+	result := engine.ThinkContext(ctx)
+	if engine.IsThinking() || result.Cycles != 0 {
+		t.Errorf("Search shouldn't run with cancelled context, cycles=%d", result.Cycles)
+	}
+}
+
+func TestMCTSSeededSearch(t *testing.T) {
+	search := func(notation string, policy RolloutPolicy, seed int64) SearchResult {
+		engine := NewEngine()
+		if err := engine.SetNotation(notation); err != nil {
+			t.Fatal(err)
+		}
+		engine.SetRolloutPolicy(policy)
+		engine.SetLimits(mcts.DefaultLimits().SetCycles(5000))
+		engine.SetSeed(seed)
+		result := engine.Think()
+
+		// Only the speed depends on the time
+		result.Cps = 0
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		notation string
+		policy   RolloutPolicy
+	}{
+		{"start uniform", StartingPosition, UniformRollout{}},
+		{"start tactical", StartingPosition, TacticalRollout{}},
+		{"midgame epsilon", "8o/9/x8/9/6x2/9/2o6/9/x8 o 0", EpsilonGreedyRollout{Epsilon: DefaultRolloutEpsilon}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := search(tt.notation, tt.policy, 1234)
+			b := search(tt.notation, tt.policy, 1234)
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("Seeded searches differ:\n%v\n%v", a, b)
+			}
+
+			c := search(tt.notation, tt.policy, 4321)
+			if reflect.DeepEqual(a, c) {
+				t.Error("Different seeds gave the same search")
+			}
+		})
+	}
+}
+
+func TestEngineSearchConfig(t *testing.T) {
+	custom := mcts.DefaultSearchConfig()
+	custom.Exploration = 2
+	custom.VirtualLoss = 5
+	custom.BestChild = mcts.BestChildWinRate
+
+	a, b := NewEngine(), NewEngine()
+	if err := a.SetSearchConfig(custom); err != nil {
+		t.Fatal(err)
+	}
+	invalid := custom
+	invalid.VirtualLoss = -1
+	if err := a.SetSearchConfig(invalid); err == nil {
+		t.Error("Invalid config should be rejected")
+	}
+
+	// Run both at the same time, the configs are per engine
+	done := make(chan SearchResult, 2)
+	for _, engine := range []*Engine{a, b} {
+		engine.SetLimits(mcts.DefaultLimits().SetThreads(2).SetCycles(5000))
+		go func() { done <- engine.Think() }()
+	}
+	for range 2 {
+		if result := <-done; len(result.Lines) == 0 {
+			t.Error("Search should find a move")
+		}
+	}
+
+	if a.SearchConfig() != custom || b.SearchConfig() != mcts.DefaultSearchConfig() {
+		t.Errorf("Configs were changed: a=%v, b=%v", a.SearchConfig(), b.SearchConfig())
+	}
+
+	// Virtual loss is fully reverted, with any value
+	for _, engine := range []*Engine{a, b} {
+		for i := range engine.Mcts().Root.Children {
+			if vl := engine.Mcts().Root.Children[i].VirtualLoss(); vl != 0 {
+				t.Fatalf("Virtual loss %d left after the search", vl)
+			}
+		}
+	}
+}
+
+func TestMCTSConfigDuringSearch(t *testing.T) {
+	// Running search uses its own copy of the config (run with -race)
+	tree := NewUtttMCTS(*NewPosition())
+	tree.Limits().SetThreads(2).SetCycles(20000)
+	tree.StatsListener().OnBestMoveChange(func(s mcts.ListenerTreeStats[PosType]) {})
+	tree.AsyncSearch()
+
+	cfg := mcts.DefaultSearchConfig()
+	cfg.BestChild = mcts.BestChildWinRate
+	cfg.Contempt = 0.2
+	tree.SetConfig(cfg)
+	tree.SetContempt(0.3)
+	tree.Synchronize()
+
+	// After the search, those apply immediately
+	if tree.Config().Contempt != 0.3 || tree.Config().BestChild != mcts.BestChildWinRate {
+		t.Errorf("Config wasn't changed: %v", tree.Config())
+	}
+	tree.SetContempt(0)
+	if best := tree.BestChild(tree.Root, mcts.BestChildWinRate); tree.RootSignature() != best.NodeSignature {
+		t.Errorf("Root signature %v, want the best win rate child %v", tree.RootSignature(), best.NodeSignature)
+	}
+}
+
+// Check that no virtual loss was left in the tree after the search
+func checkVirtualLoss(t *testing.T, node *mcts.NodeBase[PosType]) {
+	t.Helper()
+	if vl := node.VirtualLoss(); vl != 0 {
+		t.Fatalf("Node %v has virtual loss %d after the search", node.NodeSignature, vl)
+	}
+	for i := range node.Children {
+		checkVirtualLoss(t, &node.Children[i])
+	}
+}
+
+func TestMCTSCollisionStress(t *testing.T) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, threads := range []int{4, 16, 64} {
+		t.Run(fmt.Sprintf("threads=%d", threads), func(t *testing.T) {
+			tree := NewUtttMCTS(*pos)
+			cycles := uint32(0)
+
+			// Continue the search on the same tree, so the threads collide deeper in it
+			for range 3 {
+				cycles += 10000
+				tree.Limits().SetThreads(threads).SetCycles(cycles)
+
+				done := make(chan struct{})
+				go func() {
+					tree.Search()
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-time.After(30 * time.Second):
+					t.Fatal("Search didn't finish, threads are stuck")
+				}
+
+				checkVirtualLoss(t, tree.Root)
+				if visits := uint32(tree.Root.Visits()); visits < cycles || visits > cycles+uint32(threads) {
+					t.Errorf("Root visits=%d, want=%d", visits, cycles)
+				}
+				if int(tree.Size()) != tree.Count() {
+					t.Errorf("Size=%d, want=%d", tree.Size(), tree.Count())
+				}
+			}
+			t.Logf("collision factor=%.4f", tree.CollisionFactor())
+		})
+	}
+}
+
+func BenchmarkMCTSThreads(b *testing.B) {
+	pos, err := FromNotation(StartingPosition)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, threads := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			tree := NewUtttMCTS(*pos)
+			collisions := 0.0
+			cps := 0.0
+			for i := 0; i < b.N; i++ {
+				tree.Reset()
+				tree.Limits().SetThreads(threads).SetCycles(20000)
+				tree.Search()
+				collisions += tree.CollisionFactor()
+				cps += float64(tree.Cps())
+			}
+			b.ReportMetric(collisions/float64(b.N), "collisions/cycle")
+			b.ReportMetric(cps/float64(b.N), "cycles/s")
+		})
+	}
+}
+
+func TestMCTSListenerEvents(t *testing.T) {
+	for _, parallel := range []mcts.ParallelMode{mcts.TreeParallel, mcts.RootParallel} {
+		t.Run(fmt.Sprintf("parallel=%d", parallel), func(t *testing.T) {
+			tree := NewUtttMCTS(*NewPosition())
+			tree.Limits().SetThreads(2).SetParallel(parallel).SetMovetime(150)
+
+			var mu sync.Mutex
+			var intervals, intervalsAfterStop int
+			var stopped bool
+			var bestMoves int
+			tree.StatsListener().
+				OnInterval(10*time.Millisecond, func(s mcts.ListenerTreeStats[PosType]) {
+					mu.Lock()
+					defer mu.Unlock()
+					intervals++
+					if stopped {
+						intervalsAfterStop++
+					}
+				}).
+				OnBestMoveChange(func(s mcts.ListenerTreeStats[PosType]) {
+					mu.Lock()
+					defer mu.Unlock()
+					bestMoves++
+				}).
+				OnStop(func(s mcts.ListenerTreeStats[PosType]) {
+					mu.Lock()
+					defer mu.Unlock()
+					stopped = true
+				})
+			tree.Search()
+
+			// Ticker shouldn't outlive the search
+			time.Sleep(30 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+
+			if intervals < 5 || intervalsAfterStop != 0 {
+				t.Errorf("Interval calls=%d (after stop=%d), want at least 5 (and none after stop)", intervals, intervalsAfterStop)
+			}
+			if bestMoves == 0 || bestMoves > int(tree.Root.Visits()) {
+				t.Errorf("Best move changes=%d, want at least 1 and less than %d cycles", bestMoves, tree.Root.Visits())
+			}
+		})
+	}
+}
+
+func TestConformance(t *testing.T) {
+	position := func(notation string) func() mcts.GameOperations[PosType] {
+		return func() mcts.GameOperations[PosType] {
+			pos := NewPosition()
+			if err := pos.FromNotation(notation); err != nil {
+				t.Fatal(err)
+			}
+			return newUtttOps(*pos)
+		}
+	}
+
+	mctstest.Run(t, mctstest.Game[PosType]{
+		New:      position(StartingPosition),
+		MaxPlies: 81,
+		Solved: []mctstest.SolvedPosition[PosType]{
+			{Name: "win in 1", New: position("xxx6/x1x6/xxx6/o3o3o/xoxoxooxo/o3o3o/ooo6/9/9 x 1"), Result: 1},
+			{Name: "loss in 2", New: position("xxx6/x1x6/xxx6/o3o3o/x1xoxooxo/o3o3o/ooo6/9/9 o 4"), Result: 0},
+		},
+	})
+}
@@ -0,0 +1,218 @@
+package mcts
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Node arena, hands out the children arrays from preallocated chunks, instead of
+// allocating each one separately. Released arrays are recycled (by their length),
+// so the tree can be pruned and grown again without producing garbage
+
+// Optional interface of the GameOperations, required to use the arena
+type ArenaExpander[T MoveLike] interface {
+	// Same as ExpandNode, but the children array must be allocated with 'alloc'
+	ExpandNodeIn(parent *NodeBase[T], alloc func(n int) []NodeBase[T]) uint32
+}
+
+const DefaultArenaChunkSize = 1 << 16
+
+// Fraction of the memory limit, at which the tree is pruned, and the target size after pruning
+const (
+	arenaPruneThreshold = 0.9
+	arenaPruneTarget    = 0.7
+)
+
+type NodeArena[T MoveLike] struct {
+	mu        sync.Mutex
+	chunkSize int
+	chunks    [][]NodeBase[T]
+	chunk     int // index of the current chunk
+	offset    int // first free node in the current chunk
+	free      map[int][][]NodeBase[T]
+}
+
+func NewNodeArena[T MoveLike](chunkSize int) *NodeArena[T] {
+	return &NodeArena[T]{
+		chunkSize: max(1, chunkSize),
+		free:      make(map[int][][]NodeBase[T]),
+	}
+}
+
+// Get a zeroed array of 'n' nodes, safe to call from multiple threads
+func (arena *NodeArena[T]) Alloc(n int) []NodeBase[T] {
+	if n == 0 {
+		return nil
+	}
+	if n > arena.chunkSize {
+		return make([]NodeBase[T], n)
+	}
+
+	arena.mu.Lock()
+	defer arena.mu.Unlock()
+
+	// Reuse the released array
+	if blocks := arena.free[n]; len(blocks) > 0 {
+		block := blocks[len(blocks)-1]
+		arena.free[n] = blocks[:len(blocks)-1]
+		return block
+	}
+
+	// Move to the next chunk, the rest of the current one is wasted
+	if len(arena.chunks) == 0 || arena.offset+n > arena.chunkSize {
+		if len(arena.chunks) > 0 {
+			arena.chunk++
+		}
+		if arena.chunk == len(arena.chunks) {
+			arena.chunks = append(arena.chunks, make([]NodeBase[T], arena.chunkSize))
+		}
+		arena.offset = 0
+	}
+
+	block := arena.chunks[arena.chunk][arena.offset : arena.offset+n : arena.offset+n]
+	arena.offset += n
+	return block
+}
+
+// Give back the array, it will be cleared and handed out again by Alloc.
+// The array doesn't have to come from the arena
+func (arena *NodeArena[T]) Release(block []NodeBase[T]) {
+	if len(block) == 0 {
+		return
+	}
+	clear(block)
+
+	arena.mu.Lock()
+	defer arena.mu.Unlock()
+	arena.free[len(block)] = append(arena.free[len(block)], block)
+}
+
+// Release the whole subtree below the node (but not the node itself),
+// returns the number of released nodes
+func (arena *NodeArena[T]) ReleaseSubtree(node *NodeBase[T]) uint32 {
+	released := uint32(len(node.Children))
+	for i := range node.Children {
+		released += arena.ReleaseSubtree(&node.Children[i])
+	}
+	arena.Release(node.Children)
+	node.Children = nil
+	return released
+}
+
+// Recycle all of the memory, the tree using the arena must be discarded
+func (arena *NodeArena[T]) Reset() {
+	arena.mu.Lock()
+	defer arena.mu.Unlock()
+
+	for _, chunk := range arena.chunks {
+		clear(chunk)
+	}
+	clear(arena.free)
+	arena.chunk, arena.offset = 0, 0
+}
+
+// Number of nodes preallocated by the arena
+func (arena *NodeArena[T]) Capacity() int {
+	arena.mu.Lock()
+	defer arena.mu.Unlock()
+	return len(arena.chunks) * arena.chunkSize
+}
+
+// Use the node arena for the children arrays, requires the GameOperations to implement
+// the ArenaExpander. With the arena, the discarded subtrees are recycled after AdvanceRoot,
+// and if the memory limit is set, the least visited subtrees are pruned, when the tree
+// gets close to the limit (instead of stopping the growth). Pass nil to disable
+func (mcts *MCTS[T]) SetArena(arena *NodeArena[T]) {
+	mcts.arena = arena
+}
+
+func (mcts *MCTS[T]) Arena() *NodeArena[T] {
+	return mcts.arena
+}
+
+// Expand the node, using the arena if it's enabled
+func (mcts *MCTS[T]) expandNode(ops GameOperations[T], node *NodeBase[T]) uint32 {
+	if mcts.arena != nil {
+		if expander, ok := ops.(ArenaExpander[T]); ok {
+			return expander.ExpandNodeIn(node, mcts.arena.Alloc)
+		}
+	}
+	return ops.ExpandNode(node)
+}
+
+// Maximum number of nodes, based on the memory limit, 0 if there is no limit
+func (mcts *MCTS[T]) maxTreeSize() uint32 {
+	limits := mcts.Limits()
+	if limits.ByteSize == DefaultByteSizeLimit {
+		return 0
+	}
+	return uint32(limits.ByteSize / int64(unsafe.Sizeof(NodeBase[T]{})))
+}
+
+// Wheter the tree is close to the memory limit and should be pruned
+func (mcts *MCTS[T]) needsPruning() bool {
+	maxSize := mcts.maxTreeSize()
+	return mcts.arena != nil && !mcts.dag && maxSize > 0 && float64(mcts.Size()) >= arenaPruneThreshold*float64(maxSize)
+}
+
+// Collapse the least visited subtrees (keeping their nodes' statistics) into leaves,
+// until the tree's size drops to the target. Must be called with the search threads
+// paused (see searchTree), returns the number of released nodes
+func (mcts *MCTS[T]) prune(target uint32) uint32 {
+	// Collect the expanded nodes, except the root
+	candidates := make([]*NodeBase[T], 0, mcts.Size()/4)
+	var collect func(node *NodeBase[T])
+	collect = func(node *NodeBase[T]) {
+		for i := range node.Children {
+			if child := &node.Children[i]; child.Expanded() {
+				candidates = append(candidates, child)
+				collect(child)
+			}
+		}
+	}
+	collect(mcts.Root)
+
+	slices.SortFunc(candidates, func(a, b *NodeBase[T]) int {
+		return int(a.RealVisits() - b.RealVisits())
+	})
+
+	released := uint32(0)
+	for _, node := range candidates {
+		if mcts.Size() <= target {
+			break
+		}
+
+		// Already released, as a part of the other subtree
+		if !node.Expanded() {
+			continue
+		}
+
+		// Collapse into a leaf first, so the node is never expanded without the children
+		node.SetFlag(atomic.LoadUint32(&node.Flags) &^ ExpandedMask)
+		n := mcts.arena.ReleaseSubtree(node)
+		mcts.size.Add(^(n - 1))
+		released += n
+	}
+	return released
+}
+
+// Release the nodes, which are no longer a part of the tree, after promoting
+// the last node of the 'path' (starting at the old root) to the new root
+func (mcts *MCTS[T]) releasePath(path []*NodeBase[T]) {
+	if mcts.arena == nil || mcts.dag {
+		return
+	}
+
+	// Go from the bottom, since releasing the parent's children clears the path node
+	for i := len(path) - 2; i >= 0; i-- {
+		for j := range path[i].Children {
+			if child := &path[i].Children[j]; child != path[i+1] {
+				mcts.arena.ReleaseSubtree(child)
+			}
+		}
+		mcts.arena.Release(path[i].Children)
+		path[i].Children = nil
+	}
+}
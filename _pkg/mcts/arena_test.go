@@ -0,0 +1,85 @@
+package mcts
+
+import "testing"
+
+func TestNodeArenaAlloc(t *testing.T) {
+	arena := NewNodeArena[int](16)
+
+	a, b := arena.Alloc(10), arena.Alloc(4)
+	if len(a) != 10 || len(b) != 4 || cap(a) != 10 {
+		t.Fatalf("Invalid block sizes len(a)=%d cap(a)=%d len(b)=%d", len(a), cap(a), len(b))
+	}
+	if &a[0] == &b[0] || &a[9] == &b[0] {
+		t.Error("Blocks shouldn't overlap")
+	}
+
+	// Doesn't fit into the rest of the chunk
+	if c := arena.Alloc(8); len(c) != 8 || arena.Capacity() != 32 {
+		t.Errorf("Expected a new chunk, capacity=%d", arena.Capacity())
+	}
+
+	// Released block is cleared and reused
+	a[0].NodeSignature = 5
+	a[0].SetVvl(3, 0)
+	arena.Release(a)
+	reused := arena.Alloc(10)
+	if &reused[0] != &a[0] {
+		t.Error("Released block should be reused")
+	}
+	if reused[0].NodeSignature != 0 || reused[0].Visits() != 0 {
+		t.Error("Reused block should be cleared")
+	}
+
+	// Too large for the chunks
+	if big := arena.Alloc(100); len(big) != 100 || arena.Capacity() != 32 {
+		t.Errorf("Large block shouldn't use the chunks, capacity=%d", arena.Capacity())
+	}
+
+	arena.Reset()
+	if d := arena.Alloc(10); &d[0] != &a[0] {
+		t.Error("After reset, the first chunk should be reused")
+	}
+}
+
+func TestArenaPrune(t *testing.T) {
+	// root -> {1, 2}, 1 -> {3, 4}, 2 -> {5, 6}, 3 -> {7}
+	arena := NewNodeArena[int](64)
+	tree := &MCTS[int]{Root: &NodeBase[int]{}, arena: arena}
+	expand := func(node *NodeBase[int], visits int32, children ...int) *NodeBase[int] {
+		node.Children = arena.Alloc(len(children))
+		for i, c := range children {
+			node.Children[i] = *NewBaseNode(node, c, false)
+		}
+		node.SetFlag(ExpandedMask)
+		node.SetVvl(visits, 0)
+		return node
+	}
+
+	root := expand(tree.Root, 100, 1, 2)
+	first := expand(&root.Children[0], 70, 3, 4)
+	second := expand(&root.Children[1], 30, 5, 6)
+	expand(&first.Children[0], 40, 7)
+	tree.size.Store(uint32(countTreeNodes(root, nil)))
+
+	// The least visited subtree (2) goes first
+	if released := tree.prune(6); released != 2 || tree.Size() != 6 {
+		t.Fatalf("Released=%d size=%d, want=2, 6", released, tree.Size())
+	}
+	if second.Expanded() || second.Children != nil || second.Visits() != 30 {
+		t.Errorf("Node 2 should be collapsed, keeping its visits=%d", second.Visits())
+	}
+	if !first.Expanded() {
+		t.Error("Node 1 shouldn't be pruned yet")
+	}
+
+	// Then node 3, and node 1
+	tree.prune(1)
+	if tree.Size() != 3 || first.Expanded() || tree.Count() != 3 {
+		t.Errorf("Size=%d count=%d, want=3", tree.Size(), tree.Count())
+	}
+
+	// Collapsed node can be expanded again
+	if !second.CanExpand() {
+		t.Error("Collapsed node should be expandable")
+	}
+}
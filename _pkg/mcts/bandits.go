@@ -0,0 +1,144 @@
+package mcts
+
+import (
+	"fmt"
+	"math"
+)
+
+// Alternative bandit formulas, using the variance of the outcomes (see NodeStats.Variance)
+// or sampling from the posterior of the node's value, instead of the UCB1's fixed bound
+
+// Names of the selection policies, see NewSelectionPolicy
+var SelectionPolicyNames = []string{"ucb1", "puct", "ucb1rave", "ucb1tuned", "ucbv", "thompson"}
+
+// Get the selection policy by its name
+func NewSelectionPolicy[T MoveLike](name string) (SelectionPolicy[T], error) {
+	switch name {
+	case "ucb1":
+		return UCB1[T], nil
+	case "puct":
+		return PUCT[T], nil
+	case "ucb1rave":
+		return UCB1RAVE[T], nil
+	case "ucb1tuned":
+		return UCB1Tuned[T], nil
+	case "ucbv":
+		return UCBV[T], nil
+	case "thompson":
+		return ThompsonSampling[T], nil
+	}
+	return nil, fmt.Errorf("mcts: unknown selection policy %q, expected one of %v", name, SelectionPolicyNames)
+}
+
+// Score of the i-th child, computed by the bandit formula, 'visits' include the virtual loss
+type banditScore[T MoveLike] func(child *NodeBase[T], i int, visits int32, lnParentVisits float64, cfg *SearchConfig) float64
+
+// Choose the child with the highest score, the same way as UCB1 does: the proven
+// children are skipped, and the unvisited ones are chosen first (or scored with the FPU)
+func selectByScore[T MoveLike](parent *NodeBase[T], cfg *SearchConfig, score banditScore[T]) *NodeBase[T] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	max := math.Inf(-1)
+	index := 0
+	lnParentVisits := math.Log(float64(parent.Visits()))
+
+	for i := range parent.Children {
+		child := &parent.Children[i]
+		if child.Proven() {
+			continue
+		}
+
+		visits, vl := child.GetVvl()
+		if visits-vl == 0 {
+			if cfg.FPU < 0 {
+				return child
+			}
+			if cfg.FPU > max {
+				max = cfg.FPU
+				index = i
+			}
+			continue
+		}
+
+		if value := score(child, i, visits, lnParentVisits, cfg); value > max {
+			max = value
+			index = i
+		}
+	}
+	return &parent.Children[index]
+}
+
+// UCB1-Tuned, the exploration term is bounded by the variance of the outcomes:
+//
+// wins/visits + C * sqrt(ln(parent_visits)/visits * 4 * min(1/4, variance + sqrt(2*ln(parent_visits)/visits)))
+//
+// The factor 4 makes it the same as UCB1 for the maximum variance (1/4) of the outcomes
+func UCB1Tuned[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
+	return selectByScore(parent, cfg, func(child *NodeBase[T], i int, visits int32, lnParentVisits float64, cfg *SearchConfig) float64 {
+		n := float64(visits)
+		bound := child.Variance() + math.Sqrt(2*lnParentVisits/n)
+		return float64(child.Outcomes())/n + cfg.Exploration*math.Sqrt(lnParentVisits/n*4*math.Min(0.25, bound))
+	})
+}
+
+// UCB-V (Audibert, Munos, Szepesvari), with the exploration function E = C * ln(parent_visits):
+//
+// wins/visits + sqrt(2 * variance * E / visits) + 3 * E / visits
+func UCBV[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
+	return selectByScore(parent, cfg, func(child *NodeBase[T], i int, visits int32, lnParentVisits float64, cfg *SearchConfig) float64 {
+		n := float64(visits)
+		e := cfg.Exploration * lnParentVisits
+		return float64(child.Outcomes())/n + math.Sqrt(2*child.Variance()*e/n) + 3*e/n
+	})
+}
+
+// Thompson sampling, chooses the child with the highest value sampled from the
+// Beta(1 + wins, 1 + visits - wins) posterior (the draws count as half of a win).
+// The samples are derived from the visit counts, instead of a shared random number
+// generator, so the seeded search stays reproducible
+func ThompsonSampling[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
+	parentVisits := parent.Visits()
+	return selectByScore(parent, cfg, func(child *NodeBase[T], i int, visits int32, lnParentVisits float64, cfg *SearchConfig) float64 {
+		wins := float64(child.Outcomes())
+		random := sampleRand(deriveSeed(int64(parentVisits)<<32|int64(visits), i) ^ int64(math.Float64bits(wins)))
+		return random.beta(1+wins, 1+math.Max(0, float64(visits)-wins))
+	})
+}
+
+// Small random number generator (splitmix64), used to sample the posteriors
+type sampleRand uint64
+
+func (r *sampleRand) float64() float64 {
+	*r = sampleRand(deriveSeed(int64(*r), 0))
+	return (float64(uint64(*r)>>11) + 0.5) / (1 << 53)
+}
+
+// Standard normal sample (Box-Muller transform)
+func (r *sampleRand) normal() float64 {
+	return math.Sqrt(-2*math.Log(r.float64())) * math.Cos(2*math.Pi*r.float64())
+}
+
+// Gamma(shape, 1) sample for shape >= 1 (Marsaglia and Tsang)
+func (r *sampleRand) gamma(shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.normal()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		if math.Log(r.float64()) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// Beta(a, b) sample, both parameters should be at least 1
+func (r *sampleRand) beta(a, b float64) float64 {
+	x := r.gamma(a)
+	return x / (x + r.gamma(b))
+}
@@ -0,0 +1,87 @@
+package mcts
+
+import "testing"
+
+func TestNodeVariance(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []Result
+		want     float64
+	}{
+		{"no outcomes", nil, 0},
+		{"draws", []Result{0.5, 0.5, 0.5}, 0},
+		{"win and loss", []Result{1, 0}, 0.25},
+		{"mixed", []Result{1, 1, 0.5, 0}, 0.171875},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &NodeBase[int]{}
+			for _, outcome := range tt.outcomes {
+				node.AddOutcome(outcome)
+			}
+			if variance := node.Variance(); variance < tt.want-1e-6 || variance > tt.want+1e-6 {
+				t.Errorf("Variance=%v, want=%v", variance, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSelectionPolicy(t *testing.T) {
+	for _, name := range SelectionPolicyNames {
+		if policy, err := NewSelectionPolicy[int](name); err != nil || policy == nil {
+			t.Errorf("NewSelectionPolicy(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := NewSelectionPolicy[int]("ucb2"); err == nil {
+		t.Error("Expected an error for unknown policy")
+	}
+}
+
+func TestBanditPolicies(t *testing.T) {
+	// Both children have the same average outcome, but the first one is certain
+	// (only draws), while the second one is a coin flip
+	parent := expandTestNode(&NodeBase[int]{}, 1, 2)
+	parent.SetVvl(4000, 0)
+	steady, risky := &parent.Children[0], &parent.Children[1]
+	steady.SetVvl(2000, 0)
+	risky.SetVvl(2000, 0)
+	for i := range 2000 {
+		steady.AddOutcome(0.5)
+		risky.AddOutcome(Result(i % 2))
+	}
+
+	// With no variance, the exploration term of the steady child is lower
+	cfg := DefaultSearchConfig()
+	for name, policy := range map[string]SelectionPolicy[int]{"UCB1-Tuned": UCB1Tuned[int], "UCB-V": UCBV[int]} {
+		if selected := policy(parent, parent, &cfg); selected != risky {
+			t.Errorf("%s selected=%d, want=%d", name, selected.NodeSignature, risky.NodeSignature)
+		}
+	}
+
+	// Clearly better child is sampled almost always, and the same tree gives the same sample
+	addTestOutcomes(steady, 800)
+	first := ThompsonSampling(parent, parent, &cfg)
+	if first != steady {
+		t.Errorf("Thompson sampling selected=%d, want=%d", first.NodeSignature, steady.NodeSignature)
+	}
+	if again := ThompsonSampling(parent, parent, &cfg); again != first {
+		t.Errorf("Thompson sampling isn't deterministic, selected %d and %d", first.NodeSignature, again.NodeSignature)
+	}
+}
+
+func TestSampleBeta(t *testing.T) {
+	random := sampleRand(1)
+	const samples = 20000
+	sum := 0.0
+	for range samples {
+		x := random.beta(3, 7)
+		if x <= 0 || x >= 1 {
+			t.Fatalf("Beta sample out of range: %v", x)
+		}
+		sum += x
+	}
+	if mean := sum / samples; mean < 0.29 || mean > 0.31 {
+		t.Errorf("Beta(3, 7) mean=%v, want=0.3", mean)
+	}
+}
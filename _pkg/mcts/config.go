@@ -0,0 +1,205 @@
+package mcts
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Search parameters of a single tree, so the trees (and the engines using them)
+// can be tuned independently
+
+type SearchConfig struct {
+	Exploration     float64         `json:"exploration"`      // exploration constant (C) of the UCB policies
+	Puct            float64         `json:"puct"`             // exploration constant of the PUCT policy
+	RaveEquivalence float64         `json:"rave_equivalence"` // see UCB1RAVE
+	VirtualLoss     int32           `json:"virtual_loss"`     // added to the visits of the selected nodes, during the search
+	FPU             float64         `json:"fpu"`              // first-play urgency, see below
+	BestChild       BestChildPolicy `json:"best_child"`       // policy choosing the best move
+	ThreadScaling   bool            `json:"thread_scaling"`   // scale up the exploration with the number of threads
+	Contempt        float64         `json:"contempt"`         // see SetContempt
+	Policy          string          `json:"policy,omitempty"` // name of the selection policy (see NewSelectionPolicy), empty for the tree's one
+
+	// Time management, see TimeManager
+	MoveOverhead     int `json:"move_overhead"`       // time reserved for the communication, in ms
+	DefaultMovesToGo int `json:"default_moves_to_go"` // assumed number of moves until the end of the game, if the moves-to-go is unknown
+}
+
+// First-play urgency, the score of the unvisited children. With a negative value
+// the UCB policies always visit the unvisited children first, and PUCT values them as a draw
+const DefaultFPU float64 = -1
+
+func DefaultSearchConfig() SearchConfig {
+	return SearchConfig{
+		Exploration:     0.75,
+		Puct:            1.5,
+		RaveEquivalence: 500,
+		VirtualLoss:     2,
+		FPU:             DefaultFPU,
+		BestChild:       BestChildMostVisits,
+
+		MoveOverhead:     30,
+		DefaultMovesToGo: 20,
+	}
+}
+
+// Check if the parameters make sense, the ranges match the engine options
+func (cfg SearchConfig) Validate() error {
+	// False for NaN as well
+	inRange := func(value, lo, hi float64) bool {
+		return value >= lo && value <= hi
+	}
+
+	switch {
+	case !inRange(cfg.Exploration, 0, 10) || !inRange(cfg.Puct, 0, 10):
+		return fmt.Errorf("mcts: exploration must be in [0, 10] (exploration=%v, puct=%v)", cfg.Exploration, cfg.Puct)
+	case !inRange(cfg.RaveEquivalence, 1, 1e6):
+		return fmt.Errorf("mcts: rave equivalence must be in [1, 1e6], got %v", cfg.RaveEquivalence)
+	case cfg.VirtualLoss < 0 || cfg.VirtualLoss > 100:
+		return fmt.Errorf("mcts: virtual loss must be in [0, 100], got %d", cfg.VirtualLoss)
+	case !inRange(cfg.FPU, -1, 1):
+		return fmt.Errorf("mcts: fpu must be in [-1, 1], got %v", cfg.FPU)
+	case cfg.BestChild != BestChildMostVisits && cfg.BestChild != BestChildWinRate:
+		return fmt.Errorf("mcts: unknown best child policy %d", cfg.BestChild)
+	case !inRange(cfg.Contempt, -0.5, 0.5):
+		return fmt.Errorf("mcts: contempt must be in [-0.5, 0.5], got %v", cfg.Contempt)
+	case cfg.Policy != "" && !slices.Contains(SelectionPolicyNames, cfg.Policy):
+		return fmt.Errorf("mcts: unknown selection policy %q, expected one of %v", cfg.Policy, SelectionPolicyNames)
+	case cfg.MoveOverhead < 0 || cfg.MoveOverhead > 5000:
+		return fmt.Errorf("mcts: move overhead must be in [0, 5000] ms, got %d", cfg.MoveOverhead)
+	case cfg.DefaultMovesToGo < 1 || cfg.DefaultMovesToGo > 100:
+		return fmt.Errorf("mcts: default moves to go must be in [1, 100], got %d", cfg.DefaultMovesToGo)
+	}
+	return nil
+}
+
+func (cfg SearchConfig) String() string {
+	data, _ := json.Marshal(cfg)
+	return string(data)
+}
+
+// Names of the parameters, accepted by Set
+var SearchConfigNames = []string{"exploration", "puct", "rave", "virtualloss", "fpu", "bestchild", "threadscaling", "contempt", "policy",
+	"moveoverhead", "defaultmovestogo"}
+
+// Set the parameter by its name (see SearchConfigNames), parsing the value from a string
+func (cfg *SearchConfig) Set(name, value string) error {
+	var err error
+	parseFloat := func(target *float64) {
+		*target, err = strconv.ParseFloat(value, 64)
+	}
+	parseInt := func(target *int) {
+		*target, err = strconv.Atoi(value)
+	}
+
+	switch name {
+	case "exploration":
+		parseFloat(&cfg.Exploration)
+	case "puct":
+		parseFloat(&cfg.Puct)
+	case "rave":
+		parseFloat(&cfg.RaveEquivalence)
+	case "virtualloss":
+		var vl int64
+		vl, err = strconv.ParseInt(value, 10, 32)
+		cfg.VirtualLoss = int32(vl)
+	case "fpu":
+		parseFloat(&cfg.FPU)
+	case "bestchild":
+		cfg.BestChild, err = ParseBestChildPolicy(value)
+	case "threadscaling":
+		cfg.ThreadScaling, err = strconv.ParseBool(value)
+	case "contempt":
+		parseFloat(&cfg.Contempt)
+	case "policy":
+		if value != "" && !slices.Contains(SelectionPolicyNames, value) {
+			err = fmt.Errorf("unknown selection policy %q, expected one of %v", value, SelectionPolicyNames)
+		} else {
+			cfg.Policy = value
+		}
+	case "moveoverhead":
+		parseInt(&cfg.MoveOverhead)
+	case "defaultmovestogo":
+		parseInt(&cfg.DefaultMovesToGo)
+	default:
+		return fmt.Errorf("mcts: unknown search parameter %q, expected one of %v", name, SearchConfigNames)
+	}
+
+	if err != nil {
+		return fmt.Errorf("mcts: invalid value of %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get the config used by the search with given number of threads,
+// with the exploration scaled up if ThreadScaling is set
+func (cfg SearchConfig) forThreads(threads int) SearchConfig {
+	if !cfg.ThreadScaling {
+		return cfg
+	}
+
+	// 1.5 times higher with 16 threads
+	scale := 1 + math.Log2(float64(max(1, threads)))/8
+	cfg.Exploration *= scale
+	cfg.Puct *= scale
+	return cfg
+}
+
+// Set the search parameters, used from the next search on (the best child
+// policy and the contempt apply immediately, unless the search is running)
+func (mcts *MCTS[T]) SetConfig(cfg SearchConfig) {
+	mcts.config = cfg
+	mcts.applyResultConfig()
+}
+
+// Choose the best child of the last search with the current best child policy and contempt,
+// the running search keeps its own ones, see setupSearch
+func (mcts *MCTS[T]) applyResultConfig() {
+	if mcts.threads.Load() <= 0 {
+		mcts.search.BestChild, mcts.search.Contempt = mcts.config.BestChild, mcts.config.Contempt
+	}
+}
+
+func (mcts *MCTS[T]) Config() SearchConfig {
+	return mcts.config
+}
+
+// Names of the best child policies, see ParseBestChildPolicy
+var bestChildPolicyNames = map[BestChildPolicy]string{
+	BestChildMostVisits: "visits",
+	BestChildWinRate:    "winrate",
+}
+
+func (policy BestChildPolicy) String() string {
+	if name, ok := bestChildPolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("BestChildPolicy(%d)", int(policy))
+}
+
+// Get the best child policy by its name, either "visits" or "winrate"
+func ParseBestChildPolicy(name string) (BestChildPolicy, error) {
+	for policy, n := range bestChildPolicyNames {
+		if n == name {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("mcts: unknown best child policy %q, expected visits or winrate", name)
+}
+
+func (policy BestChildPolicy) MarshalText() ([]byte, error) {
+	if _, ok := bestChildPolicyNames[policy]; !ok {
+		return nil, fmt.Errorf("mcts: unknown best child policy %d", int(policy))
+	}
+	return []byte(policy.String()), nil
+}
+
+func (policy *BestChildPolicy) UnmarshalText(text []byte) error {
+	p, err := ParseBestChildPolicy(string(text))
+	if err == nil {
+		*policy = p
+	}
+	return err
+}
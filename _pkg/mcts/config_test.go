@@ -0,0 +1,136 @@
+package mcts
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestSearchConfigSet(t *testing.T) {
+	tests := []struct {
+		name, value string
+		valid       bool
+		check       func(cfg SearchConfig) bool
+	}{
+		{"exploration", "1.25", true, func(cfg SearchConfig) bool { return cfg.Exploration == 1.25 }},
+		{"virtualloss", "3", true, func(cfg SearchConfig) bool { return cfg.VirtualLoss == 3 }},
+		{"fpu", "1.1", true, func(cfg SearchConfig) bool { return cfg.FPU == 1.1 }},
+		{"bestchild", "winrate", true, func(cfg SearchConfig) bool { return cfg.BestChild == BestChildWinRate }},
+		{"threadscaling", "true", true, func(cfg SearchConfig) bool { return cfg.ThreadScaling }},
+		{"contempt", "0.1", true, func(cfg SearchConfig) bool { return cfg.Contempt == 0.1 }},
+		{"policy", "thompson", true, func(cfg SearchConfig) bool { return cfg.Policy == "thompson" }},
+		{"policy", "", true, func(cfg SearchConfig) bool { return cfg.Policy == "" }},
+		{"moveoverhead", "100", true, func(cfg SearchConfig) bool { return cfg.MoveOverhead == 100 }},
+		{"defaultmovestogo", "30", true, func(cfg SearchConfig) bool { return cfg.DefaultMovesToGo == 30 }},
+		{"moveoverhead", "0.5", false, nil},
+		{"policy", "random", false, nil},
+		{"bestchild", "random", false, nil},
+		{"virtualloss", "1.5", false, nil},
+		{"unknown", "1", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			cfg := DefaultSearchConfig()
+			err := cfg.Set(tt.name, tt.value)
+			if (err == nil) != tt.valid {
+				t.Fatalf("err=%v, valid=%v", err, tt.valid)
+			}
+			if tt.valid && !tt.check(cfg) {
+				t.Errorf("Parameter not set, config=%v", cfg)
+			}
+		})
+	}
+}
+
+func TestSearchConfigValidate(t *testing.T) {
+	if err := DefaultSearchConfig().Validate(); err != nil {
+		t.Errorf("Default config is invalid: %v", err)
+	}
+
+	invalid := []func(cfg *SearchConfig){
+		func(cfg *SearchConfig) { cfg.Exploration = -1 },
+		func(cfg *SearchConfig) { cfg.VirtualLoss = -1 },
+		func(cfg *SearchConfig) { cfg.RaveEquivalence = 0 },
+		func(cfg *SearchConfig) { cfg.BestChild = 5 },
+		func(cfg *SearchConfig) { cfg.Contempt = 1 },
+		func(cfg *SearchConfig) { cfg.Policy = "ucb2" },
+		func(cfg *SearchConfig) { cfg.Exploration = 11 },
+		func(cfg *SearchConfig) { cfg.Puct = math.NaN() },
+		func(cfg *SearchConfig) { cfg.RaveEquivalence = math.Inf(1) },
+		func(cfg *SearchConfig) { cfg.VirtualLoss = 101 },
+		func(cfg *SearchConfig) { cfg.FPU = 1.5 },
+		func(cfg *SearchConfig) { cfg.FPU = math.NaN() },
+		func(cfg *SearchConfig) { cfg.Contempt = math.NaN() },
+		func(cfg *SearchConfig) { cfg.MoveOverhead = -1 },
+		func(cfg *SearchConfig) { cfg.DefaultMovesToGo = 0 },
+	}
+	for i, modify := range invalid {
+		cfg := DefaultSearchConfig()
+		modify(&cfg)
+		if cfg.Validate() == nil {
+			t.Errorf("Config %d should be invalid: %v", i, cfg)
+		}
+	}
+
+	// Best child policy is encoded by its name
+	cfg := DefaultSearchConfig()
+	cfg.BestChild = BestChildWinRate
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded SearchConfig
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != cfg {
+		t.Errorf("Decoded=%v, want=%v (err=%v, json=%s)", decoded, cfg, err, data)
+	}
+}
+
+func TestSearchConfigThreads(t *testing.T) {
+	cfg := DefaultSearchConfig()
+	if scaled := cfg.forThreads(16); scaled != cfg {
+		t.Errorf("Exploration shouldn't be scaled without ThreadScaling, got %v", scaled.Exploration)
+	}
+
+	cfg.ThreadScaling = true
+	if scaled := cfg.forThreads(1); scaled.Exploration != cfg.Exploration {
+		t.Errorf("Single thread exploration=%v, want=%v", scaled.Exploration, cfg.Exploration)
+	}
+	if scaled := cfg.forThreads(4); scaled.Exploration <= cfg.Exploration {
+		t.Errorf("Exploration with 4 threads=%v, should be greater than %v", scaled.Exploration, cfg.Exploration)
+	}
+}
+
+func TestSelectionFPU(t *testing.T) {
+	// Unvisited second child, the first one with a good value
+	parent := expandTestNode(&NodeBase[int]{}, 1, 2)
+	parent.SetVvl(10, 0)
+	parent.Children[0].SetVvl(10, 0)
+	addTestOutcomes(&parent.Children[0], 7)
+
+	tests := []struct {
+		name   string
+		policy SelectionPolicy[int]
+		fpu    float64
+		want   int
+	}{
+		{"UCB1 unvisited first", UCB1[int], DefaultFPU, 2},
+		{"UCB1 low urgency", UCB1[int], 0.1, 1},
+		{"UCB1 high urgency", UCB1[int], 10, 2},
+		{"RAVE low urgency", UCB1RAVE[int], 0.1, 1},
+		{"PUCT draw", PUCT[int], DefaultFPU, 2},
+		{"UCB1-Tuned unvisited first", UCB1Tuned[int], DefaultFPU, 2},
+		{"UCB-V low urgency", UCBV[int], 0.1, 1},
+		{"Thompson high urgency", ThompsonSampling[int], 10, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultSearchConfig()
+			cfg.FPU = tt.fpu
+			if selected := tt.policy(parent, parent, &cfg); selected.NodeSignature != tt.want {
+				t.Errorf("Selected=%d, want=%d", selected.NodeSignature, tt.want)
+			}
+		})
+	}
+}
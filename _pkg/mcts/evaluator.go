@@ -0,0 +1,148 @@
+package mcts
+
+import "math"
+
+// Heuristic (or learned) evaluation of the game state, used instead of (or mixed with)
+// the random rollouts. Should be implemented by the GameOperations, since each search
+// thread has its own clone of them, the evaluator always reads the thread's game state
+type Evaluator[T MoveLike] interface {
+	// Evaluate the game state reached after traversing to 'node'. Returns the value of the
+	// position from the perspective of the side to move (the same as Rollout), and the prior
+	// probabilities of the node's children (in the same order), nil if the node has no children
+	Evaluate(node *NodeBase[T]) (Result, []float32)
+}
+
+// Polynomial upper confidence bound (AlphaZero-like selection), uses the priors
+// of the children set by the Evaluator. If there are no priors, every child gets the same one.
+// Unvisited children are valued with the first-play urgency, or as a draw (0.5) if it isn't set
+func PUCT[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
+
+	// Same as in UCB1, terminal node has no children
+	if parent.Terminal() {
+		return parent
+	}
+
+	max := math.Inf(-1)
+	index := 0
+	sqrtParentVisits := math.Sqrt(float64(parent.Visits()))
+	uniform := 1 / float32(len(parent.Children))
+	fpu := 0.5
+	if cfg.FPU >= 0 {
+		fpu = cfg.FPU
+	}
+	var child *NodeBase[T]
+
+	for i := 0; i < len(parent.Children); i++ {
+		child = &parent.Children[i]
+
+		// Skip the subtrees with known result
+		if child.Proven() {
+			continue
+		}
+
+		// Virtual loss is included in the visits, lowering the exploitation term
+		visits := child.Visits()
+		q := fpu
+		if visits > 0 {
+			q = float64(child.Outcomes()) / float64(visits)
+		}
+
+		prior := child.Prior()
+		if prior == 0 {
+			prior = uniform
+		}
+
+		// PUCT : Q + C * P * sqrt(parent_visits) / (1 + visits)
+		puct := q + cfg.Puct*float64(prior)*sqrtParentVisits/float64(1+visits)
+		if puct > max {
+			max = puct
+			index = i
+		}
+	}
+
+	return &parent.Children[index]
+}
+
+// Prior probability of choosing this node, set by the Evaluator on expansion
+func (node *NodeBase[T]) Prior() float32 {
+	return node.prior
+}
+
+// Set the prior probability, should be called before the node is visible to
+// the other search threads (before FinishExpanding of its parent)
+func (node *NodeBase[T]) SetPrior(prior float32) {
+	node.prior = prior
+}
+
+// Set how the leaf nodes are valued, if the GameOperations implement the Evaluator:
+// 0 - only random rollouts (default), 1 - only the evaluator, in between - weighted
+// average of both (mix * evaluation + (1 - mix) * rollout). If the mix is greater than 0,
+// the evaluator also sets the priors of the children on expansion
+func (mcts *MCTS[T]) SetEvalMix(mix float64) {
+	mcts.evalMix = math.Max(0, math.Min(1, mix))
+}
+
+func (mcts *MCTS[T]) EvalMix() float64 {
+	return mcts.evalMix
+}
+
+// Set the node selection policy, used unless SearchConfig.Policy names the other one
+func (mcts *MCTS[T]) SetSelectionPolicy(policy SelectionPolicy[T]) {
+	mcts.selection_policy = policy
+	mcts.policy = policy
+}
+
+// Get the evaluator of given game operations, nil if evaluation is disabled
+func (mcts *MCTS[T]) evaluator(ops GameOperations[T]) Evaluator[T] {
+	if mcts.evalMix <= 0 {
+		return nil
+	}
+	if evaluator, ok := ops.(Evaluator[T]); ok {
+		return evaluator
+	}
+	return nil
+}
+
+// Value of the node, evaluated while setting its children's priors. The expanded node
+// is the leaf of that cycle, so the value is reused instead of evaluating it again
+type leafEval[T MoveLike] struct {
+	node  *NodeBase[T]
+	value Result
+}
+
+// Set the children's priors, the node must be already expanded (or being expanded),
+// returns the node's value from the same evaluation
+func (mcts *MCTS[T]) setPriors(evaluator Evaluator[T], node *NodeBase[T]) Result {
+	if evaluator == nil {
+		return 0
+	}
+
+	value, priors := evaluator.Evaluate(node)
+	for i := 0; i < len(priors) && i < len(node.Children); i++ {
+		node.Children[i].prior = priors[i]
+	}
+	return value
+}
+
+// Get the value of the leaf node, from the perspective of its side to move, and wheter
+// the rollout was played. Terminal nodes always use the rollout (the result is exact).
+// If the leaf was evaluated on its expansion (see leafEval), that value is used
+func (mcts *MCTS[T]) leafValue(ops GameOperations[T], evaluator Evaluator[T], node *NodeBase[T], eval *leafEval[T]) (Result, bool) {
+	if evaluator == nil || node.Terminal() {
+		return ops.Rollout(), true
+	}
+
+	var value Result
+	if eval != nil && eval.node == node {
+		value = eval.value
+		eval.node = nil
+	} else {
+		value, _ = evaluator.Evaluate(node)
+	}
+	if mcts.evalMix >= 1 {
+		return value, false
+	}
+
+	mix := Result(mcts.evalMix)
+	return mix*value + (1-mix)*ops.Rollout(), true
+}
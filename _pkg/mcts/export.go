@@ -0,0 +1,135 @@
+package mcts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// Tree exporter, for inspecting the search tree (as Graphviz DOT or JSON)
+
+// Which part of the tree should be exported
+type ExportOptions struct {
+	MaxDepth  int   // maximum depth below the starting node, 0 means only the node itself
+	MinVisits int32 // skip the children with less (real) visits than this
+}
+
+// Snapshot of the node's statistics
+type ExportNode struct {
+	Move        string       `json:"move,omitempty"` // empty for the starting node
+	Visits      int32        `json:"visits"`
+	WinRate     float64      `json:"winrate"` // from the perspective of the player who made the move
+	VirtualLoss int32        `json:"virtual_loss"`
+	Flags       string       `json:"flags"`
+	Children    []ExportNode `json:"children,omitempty"`
+}
+
+// Get the names of the set flags, joined with '|', for example "expanded|terminal"
+func FlagsString(flags uint32) string {
+	names := []struct {
+		mask uint32
+		name string
+	}{
+		{ExpandingMask, "expanding"},
+		{ExpandedMask, "expanded"},
+		{TerminalMask, "terminal"},
+		{ProvenWinMask, "win"},
+		{ProvenLossMask, "loss"},
+		{ProvenDrawMask, "draw"},
+	}
+
+	set := make([]string, 0, len(names))
+	for _, n := range names {
+		if flags&n.mask != 0 {
+			set = append(set, n.name)
+		}
+	}
+	if len(set) == 0 {
+		return "leaf"
+	}
+	return strings.Join(set, "|")
+}
+
+// Take a snapshot of the subtree, starting at 'node'. Safe to call during the search,
+// the statistics are read atomically, and only the expanded nodes' children are visited
+func ExportTree[T MoveLike](node *NodeBase[T], opts ExportOptions) ExportNode {
+	return exportNode(node, opts, 0)
+}
+
+func exportNode[T MoveLike](node *NodeBase[T], opts ExportOptions, depth int) ExportNode {
+	visits, vl := node.GetVvl()
+	flags := atomic.LoadUint32(&node.Flags)
+	export := ExportNode{
+		Visits:      visits - vl,
+		VirtualLoss: vl,
+		Flags:       FlagsString(flags),
+	}
+
+	if depth > 0 {
+		export.Move = fmt.Sprint(node.NodeSignature)
+	}
+	if visits > 0 {
+		export.WinRate = float64(node.Outcomes()) / float64(visits)
+	}
+
+	if depth >= opts.MaxDepth || flags&ExpandedMask == 0 {
+		return export
+	}
+
+	for i := range node.Children {
+		child := &node.Children[i]
+		if child.RealVisits() < opts.MinVisits {
+			continue
+		}
+		export.Children = append(export.Children, exportNode(child, opts, depth+1))
+	}
+	return export
+}
+
+// Write the subtree as indented JSON
+func WriteJSON[T MoveLike](w io.Writer, node *NodeBase[T], opts ExportOptions) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(ExportTree(node, opts))
+}
+
+// Write the subtree as Graphviz DOT graph, every node is labeled with
+// the move, visits, win rate, virtual loss and flags
+func WriteDot[T MoveLike](w io.Writer, node *NodeBase[T], opts ExportOptions) error {
+	builder := strings.Builder{}
+	builder.WriteString("digraph mcts {\n")
+	builder.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	id := 0
+	var write func(node *ExportNode) int
+	write = func(node *ExportNode) int {
+		nodeId := id
+		id++
+
+		move := node.Move
+		if move == "" {
+			move = "root"
+		}
+		fmt.Fprintf(&builder, "\tn%d [label=\"%s\\nv=%d wr=%.3f vl=%d\\n%s\"];\n",
+			nodeId, dotEscape(move), node.Visits, node.WinRate, node.VirtualLoss, node.Flags)
+
+		for i := range node.Children {
+			childId := write(&node.Children[i])
+			fmt.Fprintf(&builder, "\tn%d -> n%d;\n", nodeId, childId)
+		}
+		return nodeId
+	}
+
+	export := ExportTree(node, opts)
+	write(&export)
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
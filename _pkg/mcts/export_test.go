@@ -0,0 +1,89 @@
+package mcts
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newExportTestTree() *NodeBase[int] {
+	// root -> {1, 2}, 1 -> {3}
+	root := expandTestNode(&NodeBase[int]{}, 1, 2)
+	root.SetVvl(10, 0)
+	addTestOutcomes(root, 5)
+	first := expandTestNode(&root.Children[0], 3)
+	first.SetVvl(8, 2)
+	addTestOutcomes(first, 6)
+	first.Children[0].SetVvl(5, 0)
+	addTestOutcomes(&first.Children[0], 1)
+	root.Children[1].SetVvl(2, 0)
+	root.Children[1].SetFlag(TerminalMask | ProvenLossMask)
+	return root
+}
+
+func TestExportTree(t *testing.T) {
+	root := newExportTestTree()
+
+	tests := []struct {
+		name     string
+		opts     ExportOptions
+		children []int // number of children on each level, following the first child
+	}{
+		{"root only", ExportOptions{MaxDepth: 0}, []int{0}},
+		{"depth 1", ExportOptions{MaxDepth: 1}, []int{2, 0}},
+		{"full", ExportOptions{MaxDepth: 10}, []int{2, 1, 0}},
+		{"min visits", ExportOptions{MaxDepth: 10, MinVisits: 6}, []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := ExportTree(root, tt.opts)
+			for depth, count := range tt.children {
+				if len(node.Children) != count {
+					t.Fatalf("Depth %d: children=%d, want=%d", depth, len(node.Children), count)
+				}
+				if count > 0 {
+					node = node.Children[0]
+				}
+			}
+		})
+	}
+
+	export := ExportTree(root, ExportOptions{MaxDepth: 1})
+	first, second := export.Children[0], export.Children[1]
+	if export.Move != "" || first.Move != "1" || first.Visits != 6 || first.VirtualLoss != 2 || first.WinRate != 0.75 {
+		t.Errorf("Invalid export of the first child %+v", first)
+	}
+	if second.Flags != "terminal|loss" || export.Flags != "expanded" {
+		t.Errorf("Flags=%s, %s, want=terminal|loss, expanded", second.Flags, export.Flags)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	root := newExportTestTree()
+	opts := ExportOptions{MaxDepth: 10}
+
+	buffer := bytes.Buffer{}
+	if err := WriteJSON(&buffer, root, opts); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ExportNode
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Children) != 2 || decoded.Children[0].Children[0].Move != "3" {
+		t.Errorf("Invalid decoded tree %+v", decoded)
+	}
+
+	buffer.Reset()
+	if err := WriteDot(&buffer, root, opts); err != nil {
+		t.Fatal(err)
+	}
+	dot := buffer.String()
+	for _, want := range []string{"digraph mcts {", "n0 -> n1;", "n1 -> n2;", "n0 -> n3;", `label="root\nv=10`, "terminal|loss"} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output doesn't contain %q:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package mcts
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// Sequential halving at the root (as in the Gumbel MuZero's root search, without the
+// Gumbel noise, since the rollouts don't give any policy). The cycle budget is split
+// evenly into log2(children) rounds, in each round every remaining root child gets the same
+// number of cycles, then the worse half of them is discarded. Below the root, the
+// selection policy is used as usual. With small budgets, UCB1 spends most of the cycles
+// on the obviously bad root moves, while this way the budget goes to the best candidates
+
+type RootPolicy int
+
+const (
+	// Choose the root's children with the selection policy, like any other node
+	RootSelectionPolicy RootPolicy = iota
+	// Sequential halving over the root's children, needs the cycles limit
+	RootSequentialHalving
+)
+
+var rootPolicyNames = map[RootPolicy]string{
+	RootSelectionPolicy:   "default",
+	RootSequentialHalving: "halving",
+}
+
+func (policy RootPolicy) String() string {
+	if name, ok := rootPolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("RootPolicy(%d)", int(policy))
+}
+
+// Get the root policy by its name, either "default" or "halving"
+func ParseRootPolicy(name string) (RootPolicy, error) {
+	for policy, n := range rootPolicyNames {
+		if n == name {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("mcts: unknown root policy %q, expected default or halving", name)
+}
+
+// State of the sequential halving, shared by the search threads
+type sequentialHalving[T MoveLike] struct {
+	mu          sync.Mutex
+	candidates  []*NodeBase[T] // children still in the race
+	roundBudget int            // cycles of a single round
+	perChild    int            // cycles given to each candidate in this round
+	issued      int            // cycles issued in this round
+}
+
+// Create the sequential halving over the root's children, with given number of cycles.
+// Returns nil if there is nothing to choose from
+func newSequentialHalving[T MoveLike](root *NodeBase[T], budget int) *sequentialHalving[T] {
+	candidates := make([]*NodeBase[T], 0, len(root.Children))
+	for i := range root.Children {
+		if !root.Children[i].Proven() {
+			candidates = append(candidates, &root.Children[i])
+		}
+	}
+	if len(candidates) < 2 || budget < len(candidates) {
+		return nil
+	}
+
+	rounds := int(math.Ceil(math.Log2(float64(len(candidates)))))
+	sh := &sequentialHalving[T]{
+		candidates:  candidates,
+		roundBudget: budget / rounds,
+	}
+	sh.perChild = max(1, sh.roundBudget/len(candidates))
+	return sh
+}
+
+// Get the root's child to search next
+func (sh *sequentialHalving[T]) next() *NodeBase[T] {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if len(sh.candidates) > 1 && sh.issued >= sh.perChild*len(sh.candidates) {
+		sh.halve()
+	}
+	child := sh.candidates[sh.issued%len(sh.candidates)]
+	sh.issued++
+	return child
+}
+
+// Keep the better half of the candidates, by their average outcome
+func (sh *sequentialHalving[T]) halve() {
+	value := func(node *NodeBase[T]) float64 {
+		if visits := node.RealVisits(); visits > 0 {
+			return float64(node.Outcomes()) / float64(visits)
+		}
+		return math.Inf(-1)
+	}
+	slices.SortStableFunc(sh.candidates, func(a, b *NodeBase[T]) int {
+		va, vb := value(a), value(b)
+		switch {
+		case va > vb:
+			return -1
+		case va < vb:
+			return 1
+		}
+		return 0
+	})
+
+	sh.candidates = sh.candidates[:(len(sh.candidates)+1)/2]
+	sh.perChild = max(1, sh.roundBudget/len(sh.candidates))
+	sh.issued = 0
+}
+
+// Set up the root policy of the search, the sequential halving gets the cycles
+// left until the cycles limit. Without that limit, the selection policy is used
+func (mcts *MCTS[T]) setupRootPolicy(limits *Limits) {
+	mcts.halving = nil
+	if limits.RootPolicy == RootSequentialHalving && limits.Cycles != DefaultCyclesLimit {
+		budget := int64(limits.Cycles) - int64(mcts.Root.RealVisits())
+		mcts.halving = newSequentialHalving(mcts.Root, int(max(budget, 0)))
+	}
+}
+
+// Choose the child of the node to search, with the root policy at the root
+func (mcts *MCTS[T]) selectChild(node *NodeBase[T]) *NodeBase[T] {
+	if node == mcts.Root && mcts.halving != nil {
+		return mcts.halving.next()
+	}
+	return mcts.policy(node, mcts.Root, &mcts.search)
+}
@@ -0,0 +1,103 @@
+package mcts
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// Multi-armed bandit: the root's children are terminal, the child 'i' wins with
+// the probability values[i-1] (from the root's side to move perspective)
+type banditTestOps struct {
+	values []float64
+	arm    int
+	random *rand.Rand
+}
+
+func (ops *banditTestOps) ExpandNode(parent *NodeBase[int]) uint32 {
+	parent.Children = make([]NodeBase[int], len(ops.values))
+	for i := range ops.values {
+		parent.Children[i] = *NewBaseNode(parent, i+1, true)
+	}
+	return uint32(len(ops.values))
+}
+func (ops *banditTestOps) Traverse(arm int) { ops.arm = arm }
+func (ops *banditTestOps) BackTraverse()    {}
+func (ops *banditTestOps) Reset()           {}
+func (ops *banditTestOps) Clone() GameOperations[int] {
+	return &banditTestOps{values: ops.values, random: rand.New(rand.NewSource(ops.random.Int63()))}
+}
+
+// Result is from the leaf's perspective, so it's a win for the root's opponent
+func (ops *banditTestOps) Rollout() Result {
+	if ops.random.Float64() < ops.values[ops.arm-1] {
+		return 0
+	}
+	return 1
+}
+
+func TestSequentialHalving(t *testing.T) {
+	values := []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.9}
+	const budget = 400
+
+	ops := &banditTestOps{values: values, random: rand.New(rand.NewSource(1))}
+	tree := NewMTCS(UCB1[int], ops, 0)
+	tree.SetLimits(DefaultLimits().SetCycles(budget).SetRootPolicy(RootSequentialHalving).SetSeed(1))
+	tree.SearchContext(context.Background(), ops)
+
+	if visits := tree.Root.Visits(); visits != budget {
+		t.Errorf("Root visits=%d, want=%d", visits, budget)
+	}
+
+	// 3 rounds of 133 cycles, in the first one each of the 8 children gets 16 cycles
+	eliminated := 0
+	for i := range tree.Root.Children {
+		if tree.Root.Children[i].Visits() == 16 {
+			eliminated++
+		}
+	}
+	if eliminated != 4 {
+		t.Errorf("Children eliminated in the first round=%d, want=4", eliminated)
+	}
+
+	if best := tree.BestChild(tree.Root, BestChildMostVisits); best.NodeSignature != len(values) {
+		t.Errorf("Best child=%d, want=%d", best.NodeSignature, len(values))
+	}
+}
+
+func TestSequentialHalvingSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   *Limits
+		children int
+		enabled  bool
+	}{
+		{"halving", DefaultLimits().SetCycles(100).SetRootPolicy(RootSequentialHalving), 4, true},
+		{"default policy", DefaultLimits().SetCycles(100), 4, false},
+		{"no cycles limit", DefaultLimits().SetMovetime(100).SetRootPolicy(RootSequentialHalving), 4, false},
+		{"budget too small", DefaultLimits().SetCycles(3).SetRootPolicy(RootSequentialHalving), 4, false},
+		{"single child", DefaultLimits().SetCycles(100).SetRootPolicy(RootSequentialHalving), 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := &banditTestOps{values: make([]float64, tt.children), random: rand.New(rand.NewSource(0))}
+			tree := NewMTCS(UCB1[int], ops, 0)
+			tree.setupRootPolicy(tt.limits)
+			if enabled := tree.halving != nil; enabled != tt.enabled {
+				t.Errorf("Sequential halving enabled=%v, want=%v", enabled, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestParseRootPolicy(t *testing.T) {
+	for policy, name := range rootPolicyNames {
+		if parsed, err := ParseRootPolicy(name); err != nil || parsed != policy {
+			t.Errorf("ParseRootPolicy(%q)=%v, %v, want=%v", name, parsed, err, policy)
+		}
+	}
+	if _, err := ParseRootPolicy("ucb"); err == nil {
+		t.Error("Expected an error for unknown policy")
+	}
+}
@@ -15,6 +15,19 @@ type Limits struct {
 	NThreads int
 	ByteSize int64
 	MultiPv  int
+	Parallel ParallelMode
+
+	// Policy choosing the root's children, see RootPolicy
+	RootPolicy RootPolicy
+
+	// Clock, used by the time manager (see TimeManager)
+	TimeLeft  [2]int // remaining time of each side in ms, negative if not set
+	Increment [2]int // increment per move of each side in ms
+	MovesToGo int    // number of moves until the next time control, 0 if unknown
+	Side      int    // side to move (index to TimeLeft and Increment), set by the game
+
+	// Seed of the search's random number generators, negative means seeded with the time
+	Seed int64
 }
 
 func (l Limits) String() string {
@@ -29,6 +42,7 @@ const (
 	DefaultMovetimeLimit int    = -1
 	DefaultByteSizeLimit int64  = -1
 	DefaultCyclesLimit   uint32 = math.MaxInt32*2 + 1
+	DefaultSeed          int64  = -1
 )
 
 func DefaultLimits() *Limits {
@@ -41,6 +55,9 @@ func DefaultLimits() *Limits {
 		NThreads: 1,
 		ByteSize: DefaultByteSizeLimit,
 		MultiPv:  1,
+		Parallel: TreeParallel,
+		TimeLeft: [2]int{DefaultMovetimeLimit, DefaultMovetimeLimit},
+		Seed:     DefaultSeed,
 	}
 }
 
@@ -76,11 +93,55 @@ func (l *Limits) SetInfinite(infinite bool) {
 	l.Infinite = infinite
 }
 
+// Set the clock of given side (0 or 1), the remaining time and the increment in ms
+func (l *Limits) SetClock(side int, timeLeft, increment int) *Limits {
+	l.TimeLeft[side] = timeLeft
+	l.Increment[side] = increment
+	l.Infinite = false
+	return l
+}
+
+func (l *Limits) SetMovesToGo(movesToGo int) *Limits {
+	l.MovesToGo = max(0, movesToGo)
+	return l
+}
+
+// Set the side to move, chooses the clock used by the time manager
+func (l *Limits) SetSide(side int) *Limits {
+	l.Side = min(max(side, 0), 1)
+	return l
+}
+
+// Wheter the search time is managed by the clock of the side to move
+func (l *Limits) UseClock() bool {
+	return l.TimeLeft[l.Side] >= 0
+}
+
+// Seed the search, so it's reproducible (in the single-threaded search with the
+// cycles, nodes or depth limit), pass a negative value to seed it with the time
+func (l *Limits) SetSeed(seed int64) *Limits {
+	l.Seed = max(seed, DefaultSeed)
+	return l
+}
+
 func (l *Limits) SetThreads(threads int) *Limits {
 	l.NThreads = max(threads, 1)
 	return l
 }
 
+// Set the multi-threaded search mode, either shared tree or root parallelization
+func (l *Limits) SetParallel(mode ParallelMode) *Limits {
+	l.Parallel = mode
+	return l
+}
+
+// Set the policy choosing the root's children, the sequential halving
+// spends the cycles limit, so set it as well
+func (l *Limits) SetRootPolicy(policy RootPolicy) *Limits {
+	l.RootPolicy = policy
+	return l
+}
+
 func (l *Limits) SetMultiPv(multipv int) *Limits {
 	l.MultiPv = max(1, multipv)
 	return l
@@ -11,32 +11,33 @@ import (
 
 // Generalized Monte-Carlo Tree Search algorithm
 
-var VirtualLoss int32 = 0
-
 // Result of the rollout, should range from [0, 1] - 0 being loss from the leaf's node perspective
 // and 1 being a win
 type Result float64
 type MoveLike comparable
 type BestChildPolicy int
 
-var ExplorationParam float64 = 0.75
-var threadExploration float64 = ExplorationParam // exploartion factor, but scaled up for multi-threaded search
-
 const (
 	BestChildMostVisits BestChildPolicy = iota
 	BestChildWinRate
 )
 
-// Will be called, when we choose this node, as it is the most promising to expand
+// Will be called, when we choose this node, as it is the most promising to expand,
+// 'cfg' is the config of the running search (see SearchConfig)
 // Warning: when using NodeStats fields, must use atomic operations (Load, Store)
 // since the search may be multi-threaded (tree parallelized)
-type SelectionPolicy[T MoveLike] func(parent, root *NodeBase[T]) *NodeBase[T]
+type SelectionPolicy[T MoveLike] func(parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T]
 
 // visits/virutal loss/win/loss count of the node,
 // wins, and losses should be accessed only with atomic operations
 // However to read the visit and virtual loss counts, use the methods
 type NodeStats struct {
-	sumOutcomes atomic.Uint64 // float64 value of compounded outcomes for this node with 10^-3 precision
+	// Win/draw/loss counters of the outcomes (see AddOutcome), with 10^-3 precision
+	wins   atomic.Uint64
+	draws  atomic.Uint64
+	losses atomic.Uint64
+	// Sum of the squared outcomes, with the same precision, see Variance
+	squares atomic.Uint64
 
 	// This is visit counter, it cannot be read by atomic, use GetVvl() Visits() to properly read this value
 	visits atomic.Int32
@@ -44,6 +45,10 @@ type NodeStats struct {
 	// Current virtual loss applied to visits, it always meets condition: visits - virtualLoss >= 0.
 	// Read this value ONLY with GetVvl() or VirtualLoss() methods
 	virtualLoss atomic.Int32
+
+	// All-moves-as-first statistics (see rave.go), with the same precision as the outcomes
+	amafOutcomes atomic.Uint64
+	amafVisits   atomic.Int32
 }
 
 const (
@@ -51,6 +56,13 @@ const (
 	ExpandingMask uint32 = 1
 	ExpandedMask  uint32 = 2
 	TerminalMask  uint32 = 4
+
+	// Proven results (see solver.go), from the perspective of the player
+	// who made the move into the node
+	ProvenWinMask  uint32 = 8
+	ProvenLossMask uint32 = 16
+	ProvenDrawMask uint32 = 32
+	ProvenMask     uint32 = ProvenWinMask | ProvenLossMask | ProvenDrawMask
 )
 
 type NodeBase[T MoveLike] struct {
@@ -61,6 +73,7 @@ type NodeBase[T MoveLike] struct {
 	// Synchornizes read/write on visits, virtual loss and outcomes
 	// nodeMutex sync.RWMutex
 	Flags uint32 // must be read/written atomically
+	prior float32
 }
 
 func newRootNode[T MoveLike](terminated bool) *NodeBase[T] {
@@ -80,15 +93,31 @@ func NewBaseNode[T MoveLike](parent *NodeBase[T], signature T, terminated bool)
 }
 
 func (node *NodeBase[T]) AvgOutcome() Result {
-	return Result(node.sumOutcomes.Load()) / 1e3 / Result(node.Visits())
+	return node.Outcomes() / Result(node.Visits())
 }
 
+// Sum of the outcomes, a draw counts as half of a win
 func (node *NodeBase[T]) Outcomes() Result {
-	return Result(node.sumOutcomes.Load()) / 1e3
+	return Result(2*node.wins.Load()+node.draws.Load()) / (2 * outcomeScale)
 }
 
+// Add the result of one playout to the win/draw/loss counters (see SplitOutcome), and its square to the variance.
+// The result is clamped to [0, 1], so a sum of the outcomes has to be added one by one
 func (node *NodeBase[T]) AddOutcome(result Result) {
-	node.sumOutcomes.Add(uint64(result * 1e3))
+	win, draw, loss := SplitOutcome(result)
+	node.addWDL(uint64(win*outcomeScale), uint64(draw*outcomeScale), uint64(loss*outcomeScale))
+	result = min(max(result, 0), 1)
+	node.squares.Add(uint64(result * result * outcomeScale))
+}
+
+// Variance of the outcomes, 0 if there are none
+func (node *NodeBase[T]) Variance() float64 {
+	n := float64(node.wins.Load()+node.draws.Load()+node.losses.Load()) / outcomeScale
+	if n == 0 {
+		return 0
+	}
+	mean := float64(node.Outcomes()) / n
+	return max(0, float64(node.squares.Load())/outcomeScale/n-mean*mean)
 }
 
 func (node *NodeBase[T]) Visits() int32 {
@@ -165,10 +194,9 @@ func (node *NodeBase[T]) Expanding() bool {
 }
 
 // Should be called when we want to expand this node,
-// if it's possible, sets the internal flag to 'currently expanding'
+// if it's possible, sets the internal flag to 'currently expanding'.
+// The other threads reaching this node don't wait for the expansion, see selection
 func (node *NodeBase[T]) CanExpand() bool {
-	// TODO:
-	// This is causing the concurrent threads to deadlock in 'Expanding' loop
 	return atomic.CompareAndSwapUint32(&node.Flags, CanExpand, ExpandingMask)
 }
 
@@ -206,12 +234,28 @@ type TreeStats struct {
 type MCTS[T MoveLike] struct {
 	TreeStats
 	listener         *StatsListener[T]
+	events           listenerState[T]
 	Limiter          LimiterLike
 	selection_policy SelectionPolicy[T]
+	policy           SelectionPolicy[T] // used by the running search, see setupSearch
 	Root             *NodeBase[T]
 	size             atomic.Uint32
 	wg               sync.WaitGroup
 	collisionCount   atomic.Int32
+	solver           bool
+	evalMix          float64
+	rave             bool
+	parallel         rootParallelState
+	arena            *NodeArena[T]
+	pruneMu          sync.RWMutex // held by the search threads, locked for writing when pruning the tree
+	dag              bool
+	transpositions   atomic.Uint32
+	clock            *TimeManager[T] // set if the search uses the clock
+	done             <-chan struct{} // search's context Done channel, nil if it can't be cancelled
+	config           SearchConfig
+	search           SearchConfig          // config of the running (or the last) search, see setupSearch
+	threads          atomic.Int32          // number of the running search threads
+	halving          *sequentialHalving[T] // set if the root uses the sequential halving
 }
 
 // Create new base tree
@@ -225,8 +269,11 @@ func NewMTCS[T MoveLike](
 		listener:         &StatsListener[T]{},
 		Limiter:          LimiterLike(NewLimiter(uint32(unsafe.Sizeof(NodeBase[T]{})))),
 		selection_policy: selectionPolicy,
+		policy:           selectionPolicy,
 		Root:             &NodeBase[T]{Flags: flags},
+		config:           DefaultSearchConfig(),
 	}
+	mcts.search = mcts.config
 
 	// Set IsThinking to false
 	mcts.Limiter.Stop()
@@ -248,23 +295,23 @@ func (mcts *MCTS[T]) invokeListener(f ListenerFunc[T]) {
 	}
 }
 
-// Get the collision count, which is the number of times
-// the node was chosen, but it was already being expanded
-// resulting in a 'waiting' state
+// Get the collision count of the current search, which is the number of times
+// the node was chosen, but it was already being expanded by the other thread
+// (resulting in the selection being repeated, see maxCollisionRetries)
 func (mcts *MCTS[T]) CollisionCount() int32 {
 	return mcts.collisionCount.Load()
 }
 
-// Number of all collisions in the tree divided by the number of all cycles
+// Number of the collisions in the current search divided by the number of all cycles
 func (mcts *MCTS[T]) CollisionFactor() float64 {
-	if mcts.Nodes() == 0 {
+	if mcts.Nodes() == 0 || mcts.Root.Visits() == 0 {
 		return 0.0
 	}
 	return float64(mcts.collisionCount.Load()) / float64(mcts.Root.Visits())
 }
 
 func (mcts *MCTS[T]) ResetListener() {
-	mcts.listener.OnCycle(nil).OnDepth(nil).OnStop(nil)
+	mcts.listener.OnCycle(nil).OnDepth(nil).OnStop(nil).OnInterval(0, nil).OnBestMoveChange(nil)
 }
 
 func (mcts *MCTS[T]) StatsListener() *StatsListener[T] {
@@ -306,12 +353,23 @@ func (mcts *MCTS[T]) String() string {
 	return str
 }
 
-// Helper function to count tree nodes
-func countTreeNodes[T MoveLike](node *NodeBase[T]) int {
+// Helper function to count tree nodes, the children arrays already in 'seen' (keyed by
+// their first node) are skipped, so the ones shared in the DAG mode are counted once
+func countTreeNodes[T MoveLike](node *NodeBase[T], seen map[*NodeBase[T]]bool) int {
 	nodes := 1
+	if len(node.Children) == 0 {
+		return nodes
+	}
+	if seen != nil {
+		if seen[&node.Children[0]] {
+			return nodes
+		}
+		seen[&node.Children[0]] = true
+	}
+
 	for i := range node.Children {
 		if len(node.Children[i].Children) > 0 {
-			nodes += countTreeNodes(&node.Children[i])
+			nodes += countTreeNodes(&node.Children[i], seen)
 		} else {
 			nodes += 1
 		}
@@ -320,9 +378,17 @@ func countTreeNodes[T MoveLike](node *NodeBase[T]) int {
 	return nodes
 }
 
+// Count the nodes of the subtree, without the duplicates in the DAG mode
+func (mcts *MCTS[T]) countNodes(root *NodeBase[T]) int {
+	if !mcts.dag {
+		return countTreeNodes(root, nil)
+	}
+	return countTreeNodes(root, make(map[*NodeBase[T]]bool))
+}
+
 // Get the size of the tree (by counting)
 func (mcts *MCTS[T]) Count() int {
-	return countTreeNodes(mcts.Root)
+	return mcts.countNodes(mcts.Root)
 }
 
 // Get the size of the tree
@@ -341,20 +407,78 @@ func (mcts *MCTS[T]) Reset(ops GameOperations[T], isTerminated bool) {
 
 	// Reset game state and make new root
 	ops.Reset()
+	if mcts.arena != nil {
+		mcts.arena.Reset()
+	}
 	mcts.Root = newRootNode[T](isTerminated)
 	mcts.size.Store(1)
 	mcts.Root.CanExpand()
 	mcts.Root.FinishExpanding()
 
 	if !isTerminated {
-		mcts.size.Add(ops.ExpandNode(mcts.Root))
+		mcts.size.Add(mcts.expandNode(ops, mcts.Root))
+	}
+}
+
+// Promote the node reached by playing given 'moves' from the root to the new root,
+// keeping its statistics and the whole subtree. The caller should update the game
+// state beforehand (the same way as before calling Reset). Returns false if there is
+// no such path in the tree, in that case the tree should be Reset
+func (mcts *MCTS[T]) AdvanceRoot(ops GameOperations[T], moves ...T) bool {
+	// Discard running search
+	if mcts.IsThinking() {
+		mcts.Stop()
+		mcts.Synchronize()
+	}
+
+	// Find the matching node
+	path := make([]*NodeBase[T], 1, len(moves)+1)
+	path[0] = mcts.Root
+	for _, move := range moves {
+		node := findChild(path[len(path)-1], move)
+		if node == nil {
+			return false
+		}
+		path = append(path, node)
+	}
+
+	ops.Reset()
+	if node := path[len(path)-1]; node != mcts.Root {
+		mcts.Root = detachNode(node)
+		mcts.releasePath(path)
+		mcts.size.Store(uint32(mcts.countNodes(mcts.Root)))
 	}
+
+	// New root might be a leaf, expand it the same way as in Reset
+	if !mcts.Root.Terminal() && mcts.Root.CanExpand() {
+		mcts.Root.FinishExpanding()
+		mcts.size.Add(mcts.expandNode(ops, mcts.Root))
+	}
+
+	return true
+}
+
+// Make a root node out of given node, copies it's statistics and children,
+// so the parent's children array (the siblings) can be garbage collected
+func detachNode[T MoveLike](node *NodeBase[T]) *NodeBase[T] {
+	root := &NodeBase[T]{
+		Children: node.Children,
+		Flags:    atomic.LoadUint32(&node.Flags),
+	}
+	root.copyWDL(&node.NodeStats)
+	root.SetVvl(node.RealVisits(), 0)
+
+	// Re-attach the children to the new root
+	for i := range root.Children {
+		root.Children[i].Parent = root
+	}
+	return root
 }
 
 // 'the best move' in the position
 func (mcts *MCTS[T]) RootSignature() T {
 	var signature T
-	if bestChild := mcts.BestChild(mcts.Root, BestChildMostVisits); bestChild != nil {
+	if bestChild := mcts.BestChild(mcts.Root, mcts.search.BestChild); bestChild != nil {
 		signature = bestChild.NodeSignature
 	}
 	return signature
@@ -362,7 +486,7 @@ func (mcts *MCTS[T]) RootSignature() T {
 
 // Current evaluation of the position
 func (mcts *MCTS[T]) RootScore() Result {
-	if bestChild := mcts.BestChild(mcts.Root, BestChildMostVisits); bestChild != nil {
+	if bestChild := mcts.BestChild(mcts.Root, mcts.search.BestChild); bestChild != nil {
 		return bestChild.Outcomes() / Result(bestChild.Visits())
 	}
 	return Result(math.NaN())
@@ -374,6 +498,13 @@ func (mcts *MCTS[T]) BestChild(node *NodeBase[T], policy BestChildPolicy) *NodeB
 	var child *NodeBase[T]
 	maxVisits := 0
 
+	// Proven wins are always preferred, proven losses are chosen only if there is
+	// nothing else to choose from
+	if child, forced := provenBestChild(node); forced {
+		return child
+	}
+	drawValue := mcts.drawValue(node)
+
 	// DEBUG
 	// rootTurn := mcts.Root.Turn() == node.Turn()
 	// if rootTurn {
@@ -394,11 +525,35 @@ func (mcts *MCTS[T]) BestChild(node *NodeBase[T], policy BestChildPolicy) *NodeB
 	case BestChildMostVisits:
 		for i := 0; i < len(node.Children); i++ {
 			child = &node.Children[i]
+			if child.ProvenLoss() {
+				continue
+			}
 			if v := int(child.RealVisits()); v > maxVisits && v > 0 {
 				maxVisits = int(child.RealVisits())
 				bestChild = child
 			}
 		}
+
+		// With the contempt, the children visited nearly as much as the best one
+		// are compared by their value, with the draws valued by the contempt
+		if mcts.search.Contempt != 0 && bestChild != nil {
+			bestValue := bestChild.ValueWithDraw(drawValue)
+			for i := range node.Children {
+				child = &node.Children[i]
+				if child.ProvenLoss() || float64(child.RealVisits()) < contemptVisitsRatio*float64(maxVisits) {
+					continue
+				}
+				if value := child.ValueWithDraw(drawValue); value > bestValue {
+					bestValue, bestChild = value, child
+				}
+			}
+		}
+
+		// Take the proven draw, if the most visited child is expected to do worse
+		if draw := provenDrawChild(node); draw != nil && bestChild != nil &&
+			bestChild.ValueWithDraw(drawValue) < drawValue {
+			bestChild = draw
+		}
 	case BestChildWinRate:
 		// the child we choose should have at least 20% of the max visit count (from the neighbours)
 		const (
@@ -417,10 +572,23 @@ func (mcts *MCTS[T]) BestChild(node *NodeBase[T], policy BestChildPolicy) *NodeB
 		for i := 0; i < len(node.Children); i++ {
 			child = &node.Children[i]
 			real := child.RealVisits()
+			if child.ProvenLoss() {
+				continue
+			}
+
+			// Proven draw has an exact value, no matter the visit count
+			if child.ProvenDraw() {
+				if drawValue > bestWinRate {
+					bestWinRate = drawValue
+					bestChild = child
+				}
+				continue
+			}
+
 			if real > minVisitsThreshold && real > int32(minVisitsPercentageThreshold*float64(maxVisits)) {
 
 				// We optimize the winning chances, looking from the root's perspective
-				var winRate float64 = float64(child.Outcomes()) / float64(child.Visits())
+				winRate := child.ValueWithDraw(drawValue)
 
 				if winRate > bestWinRate {
 					bestWinRate = winRate
@@ -437,11 +605,82 @@ func (mcts *MCTS[T]) BestChild(node *NodeBase[T], policy BestChildPolicy) *NodeB
 	return bestChild
 }
 
+// Minimal visits of the child (relative to the most visited one), to be chosen
+// by its value instead of the visits, when the contempt is set
+const contemptVisitsRatio = 0.5
+
+// Set the contempt, used when choosing the best child (see BestChild), it lowers the value
+// of a draw for the root's side by 'contempt' (and raises it for the opponent), so positive
+// values avoid the draws, and the negative ones prefer them. Zero by default
+func (mcts *MCTS[T]) SetContempt(contempt float64) {
+	mcts.config.Contempt = contempt
+	mcts.applyResultConfig()
+}
+
+func (mcts *MCTS[T]) Contempt() float64 {
+	return mcts.config.Contempt
+}
+
+// Value of a draw for the player choosing among the node's children
+func (mcts *MCTS[T]) drawValue(node *NodeBase[T]) float64 {
+	contempt := mcts.search.Contempt
+	if contempt == 0 {
+		return 0.5
+	}
+
+	rootSide := true
+	for n := node; n != nil && n != mcts.Root; n = n.Parent {
+		rootSide = !rootSide
+	}
+	if rootSide {
+		return 0.5 - contempt
+	}
+	return 0.5 + contempt
+}
+
+// Sorting rank of the node, based on its proven result:
+// -1 for a proven win, 1 for a proven loss and 0 otherwise
+func provenRank[T MoveLike](node *NodeBase[T]) int {
+	if node.ProvenWin() {
+		return -1
+	} else if node.ProvenLoss() {
+		return 1
+	}
+	return 0
+}
+
+// Proven draw child of given node, nil if there is none
+func provenDrawChild[T MoveLike](node *NodeBase[T]) *NodeBase[T] {
+	for i := range node.Children {
+		if node.Children[i].ProvenDraw() {
+			return &node.Children[i]
+		}
+	}
+	return nil
+}
+
 type PvResult[T MoveLike] struct {
 	Root     *NodeBase[T]
 	Pv       []T
 	Terminal bool
 	Draw     bool
+	// Number of plies until the game ends, if the line is terminal: exact
+	// if the root is proven (solver mode), otherwise the length of the pv
+	MateDistance int
+}
+
+// Win/draw/loss probabilities of the line, from the perspective of the side to move
+// at the root, exact if the line's result is known
+func (pv PvResult[T]) WDL() WDL {
+	switch {
+	case pv.Terminal && pv.Draw:
+		return WDL{Draw: 1}
+	case pv.Terminal && pv.MateDistance%2 == 1:
+		return WDL{Win: 1}
+	case pv.Terminal:
+		return WDL{Loss: 1}
+	}
+	return pv.Root.WDL()
 }
 
 // Returns 'pvCount' best move lines
@@ -459,6 +698,13 @@ func (mcts *MCTS[T]) MultiPv(policy BestChildPolicy) []PvResult[T] {
 	}
 
 	slices.SortFunc(root_nodes, func(a *NodeBase[T], b *NodeBase[T]) int {
+		// Proven wins go first (the fastest one first), proven losses last
+		if ra, rb := provenRank(a), provenRank(b); ra != rb {
+			return ra - rb
+		} else if ra != 0 {
+			return -ra * (a.ProofDistance() - b.ProofDistance())
+		}
+
 		va, vb := a.Visits(), b.Visits()
 		if va < vb {
 			return 1
@@ -472,12 +718,21 @@ func (mcts *MCTS[T]) MultiPv(policy BestChildPolicy) []PvResult[T] {
 		// Get the Pv from this 'Root'
 		if i < child_count {
 			pv, terminal, draw := mcts.Pv(root_nodes[i], policy, true)
-			multipv = append(multipv, PvResult[T]{
+			result := PvResult[T]{
 				Root:     root_nodes[i],
 				Pv:       pv,
 				Terminal: terminal,
 				Draw:     draw,
-			})
+			}
+
+			if root_nodes[i].Proven() {
+				result.Terminal = true
+				result.Draw = root_nodes[i].ProvenDraw()
+				result.MateDistance = root_nodes[i].ProofDistance()
+			} else if terminal {
+				result.MateDistance = len(pv)
+			}
+			multipv = append(multipv, result)
 		} else {
 			break
 		}
@@ -501,14 +756,15 @@ func (mcts *MCTS[T]) PvNodes(root *NodeBase[T], policy BestChildPolicy, includeR
 		pv = append(pv, root)
 	}
 
-	if len(root.Children) == 0 {
+	if !root.Expanded() || len(root.Children) == 0 {
 		// If there are no children, we cannot go further
 		return pv, root.Terminal()
 	}
 
 	// Simply select 'best child' until we don't have any children
-	// or the node is nil
-	for len(node.Children) > 0 {
+	// or the node is nil. The children are read only after the expanded flag is set,
+	// since the pv may be built by the listener while the other threads expand the nodes
+	for node.Expanded() && len(node.Children) > 0 {
 		node = mcts.BestChild(node, policy)
 		if node == nil {
 			break
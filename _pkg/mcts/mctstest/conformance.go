@@ -0,0 +1,240 @@
+// Conformance tests of the mcts.GameOperations implementations, every game
+// should pass them (see Run), so the generic search can rely on the same behaviour
+package mctstest
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+	"uttt/_pkg/mcts"
+)
+
+type Game[T mcts.MoveLike] struct {
+	// Create the game operations in the starting position (not terminal),
+	// every call should return a new instance
+	New func() mcts.GameOperations[T]
+	// Maximum number of plies of a game, the random games can't be longer
+	MaxPlies int
+	// Positions with a known result, the solver should prove them
+	Solved []SolvedPosition[T]
+}
+
+type SolvedPosition[T mcts.MoveLike] struct {
+	Name string
+	// Create the game operations in this position (not terminal)
+	New func() mcts.GameOperations[T]
+	// Result of the game for the side to move: 1 - win, 0.5 - draw, 0 - loss
+	Result mcts.Result
+	// If not empty, the best move should be one of these
+	BestMoves []T
+	// Cycles limit of the search, 100000 if 0
+	Cycles uint32
+}
+
+// Number of the random games played in every test
+const randomGames = 20
+
+// Run all of the conformance tests on the game
+func Run[T mcts.MoveLike](t *testing.T, game Game[T]) {
+	t.Run("TraverseSymmetry", func(t *testing.T) { testTraverseSymmetry(t, game) })
+	t.Run("CloneIndependence", func(t *testing.T) { testCloneIndependence(t, game) })
+	t.Run("Terminal", func(t *testing.T) { testTerminal(t, game) })
+	t.Run("Solve", func(t *testing.T) { testSolve(t, game) })
+}
+
+// Children of the position, as the moves and their terminal flags
+type expansion[T mcts.MoveLike] struct {
+	moves    []T
+	terminal []bool
+}
+
+// Expand the current position, checking the returned number of children and their parents
+func expand[T mcts.MoveLike](t *testing.T, ops mcts.GameOperations[T]) expansion[T] {
+	t.Helper()
+	node := &mcts.NodeBase[T]{}
+	if n := ops.ExpandNode(node); int(n) != len(node.Children) {
+		t.Fatalf("ExpandNode returned %d, but added %d children", n, len(node.Children))
+	}
+
+	var e expansion[T]
+	for i := range node.Children {
+		child := &node.Children[i]
+		if child.Parent != node {
+			t.Fatalf("Child %v has a wrong parent", child.NodeSignature)
+		}
+		e.moves = append(e.moves, child.NodeSignature)
+		e.terminal = append(e.terminal, child.Terminal())
+	}
+	return e
+}
+
+func (e expansion[T]) equal(other expansion[T]) bool {
+	return slices.Equal(e.moves, other.moves) && slices.Equal(e.terminal, other.terminal)
+}
+
+// Choose the random non-terminal move, returns false if there is none
+func randomMove[T mcts.MoveLike](e expansion[T], random *rand.Rand) (T, bool) {
+	var open []T
+	for i, move := range e.moves {
+		if !e.terminal[i] {
+			open = append(open, move)
+		}
+	}
+	if len(open) == 0 {
+		var none T
+		return none, false
+	}
+	return open[random.Intn(len(open))], true
+}
+
+// Play random moves, avoiding the terminal positions, returns the expansions
+// of the visited positions (starting with the current one)
+func randomWalk[T mcts.MoveLike](t *testing.T, ops mcts.GameOperations[T], random *rand.Rand, maxPlies int) []expansion[T] {
+	t.Helper()
+	walk := []expansion[T]{expand(t, ops)}
+	for len(walk) <= maxPlies {
+		move, ok := randomMove(walk[len(walk)-1], random)
+		if !ok {
+			break
+		}
+		ops.Traverse(move)
+		walk = append(walk, expand(t, ops))
+	}
+	if len(walk) > maxPlies {
+		t.Fatalf("Game is longer than %d plies", maxPlies)
+	}
+	return walk
+}
+
+// Traverse followed by BackTraverse (and the rollouts) should restore the position
+func testTraverseSymmetry[T mcts.MoveLike](t *testing.T, game Game[T]) {
+	random := rand.New(rand.NewSource(1))
+	for range randomGames {
+		ops := game.New()
+		walk := randomWalk(t, ops, random, game.MaxPlies)
+
+		for ply := len(walk) - 1; ply >= 0; ply-- {
+			if result := ops.Rollout(); result < 0 || result > 1 {
+				t.Fatalf("Rollout result %v at ply %d, expected [0, 1]", result, ply)
+			}
+			if e := expand(t, ops); !e.equal(walk[ply]) {
+				t.Fatalf("Position at ply %d changed after the rollout: %v, want=%v", ply, e, walk[ply])
+			}
+			if ply > 0 {
+				ops.BackTraverse()
+				if e := expand(t, ops); !e.equal(walk[ply-1]) {
+					t.Fatalf("BackTraverse to ply %d gave children %v, want=%v", ply-1, e, walk[ply-1])
+				}
+			}
+		}
+	}
+}
+
+// Moves made on the clone shouldn't change the original, and the other way around
+func testCloneIndependence[T mcts.MoveLike](t *testing.T, game Game[T]) {
+	random := rand.New(rand.NewSource(2))
+	for range randomGames {
+		ops := game.New()
+		for range random.Intn(game.MaxPlies / 2) {
+			move, ok := randomMove(expand(t, ops), random)
+			if !ok {
+				break
+			}
+			ops.Traverse(move)
+		}
+
+		original := expand(t, ops)
+		clone := ops.Clone()
+		if e := expand(t, clone); !e.equal(original) {
+			t.Fatalf("Clone has children %v, want=%v", e, original)
+		}
+
+		randomWalk(t, clone, random, game.MaxPlies)
+		clone.Rollout()
+		if e := expand(t, ops); !e.equal(original) {
+			t.Fatalf("Original changed after the moves on the clone: %v, want=%v", e, original)
+		}
+
+		cloned := expand(t, clone)
+		randomWalk(t, ops, random, game.MaxPlies)
+		if e := expand(t, clone); !e.equal(cloned) {
+			t.Fatalf("Clone changed after the moves on the original: %v, want=%v", e, cloned)
+		}
+	}
+}
+
+// Every game ends within MaxPlies, and the terminal positions have an exact result:
+// the side to move either lost or it's a draw (it couldn't win with the opponent's move)
+func testTerminal[T mcts.MoveLike](t *testing.T, game Game[T]) {
+	random := rand.New(rand.NewSource(3))
+	for range randomGames {
+		ops := game.New()
+		walk := randomWalk(t, ops, random, game.MaxPlies)
+
+		// Last position has only the terminal children
+		last := walk[len(walk)-1]
+		if len(last.moves) == 0 {
+			t.Fatal("Non-terminal position has no children")
+		}
+		ops.Traverse(last.moves[random.Intn(len(last.moves))])
+
+		result := ops.Rollout()
+		if result != 0 && result != 0.5 {
+			t.Fatalf("Terminal position's result=%v, want 0 (loss) or 0.5 (draw)", result)
+		}
+		for range 5 {
+			if again := ops.Rollout(); again != result {
+				t.Fatalf("Terminal position's result changed from %v to %v", result, again)
+			}
+		}
+	}
+
+	// Search shouldn't do anything in a terminal root
+	ops := game.New()
+	walk := randomWalk(t, ops, random, game.MaxPlies)
+	ops.Traverse(walk[len(walk)-1].moves[0])
+	tree := mcts.NewMTCS(mcts.UCB1[T], ops, mcts.TerminalFlag(true))
+	tree.SetLimits(mcts.DefaultLimits().SetCycles(100))
+	tree.SearchContext(context.Background(), ops)
+	if visits := tree.Root.Visits(); visits != 0 {
+		t.Errorf("Search of the terminal root made %d visits", visits)
+	}
+}
+
+// Solver should prove the known results
+func testSolve[T mcts.MoveLike](t *testing.T, game Game[T]) {
+	for _, position := range game.Solved {
+		t.Run(position.Name, func(t *testing.T) {
+			cycles := position.Cycles
+			if cycles == 0 {
+				cycles = 100000
+			}
+
+			ops := position.New()
+			tree := mcts.NewMTCS(mcts.UCB1[T], ops, 0)
+			tree.SetSolver(true)
+			tree.SetLimits(mcts.DefaultLimits().SetCycles(cycles).SetSeed(1))
+			tree.SearchContext(context.Background(), ops)
+
+			// Root's flags are from the perspective of the player who moved into it
+			var proven bool
+			switch position.Result {
+			case 1:
+				proven = tree.Root.ProvenLoss()
+			case 0:
+				proven = tree.Root.ProvenWin()
+			default:
+				proven = tree.Root.ProvenDraw()
+			}
+			if !proven {
+				t.Fatalf("Root isn't proven with result %v for the side to move (flags=%b, visits=%d)",
+					position.Result, tree.Root.Flags, tree.Root.Visits())
+			}
+
+			if best := tree.RootSignature(); len(position.BestMoves) > 0 && !slices.Contains(position.BestMoves, best) {
+				t.Errorf("Best move=%v, want one of %v", best, position.BestMoves)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package mcts
+
+import "math"
+
+// Rapid Action Value Estimation, all-moves-as-first (AMAF) statistics are updated for every
+// child, whose move was played later in the simulation by the same player. The UCB1RAVE policy
+// blends them with the regular statistics, which helps in the early phase of the node's life
+
+// Optional interface of the GameOperations, required by the RAVE mode
+type RolloutRecorder[T MoveLike] interface {
+	// Moves played in the last Rollout call (in order), the returned slice
+	// may be reused by the next rollout
+	RolloutMoves() []T
+}
+
+// Per-thread buffers used in AMAF backpropagation
+type raveScratch[T MoveLike] struct {
+	path     []*NodeBase[T]
+	sequence []T
+	played   [2]map[T]struct{}
+}
+
+func newRaveScratch[T MoveLike]() *raveScratch[T] {
+	return &raveScratch[T]{
+		path:     make([]*NodeBase[T], 0, 64),
+		sequence: make([]T, 0, 128),
+		played:   [2]map[T]struct{}{make(map[T]struct{}), make(map[T]struct{})},
+	}
+}
+
+// Enable or disable updating the AMAF statistics, requires the GameOperations
+// to implement the RolloutRecorder. Use with UCB1RAVE selection policy
+func (mcts *MCTS[T]) SetRave(rave bool) {
+	mcts.rave = rave
+}
+
+func (mcts *MCTS[T]) Rave() bool {
+	return mcts.rave
+}
+
+// AMAF visit count
+func (node *NodeBase[T]) AmafVisits() int32 {
+	return node.amafVisits.Load()
+}
+
+// Sum of the AMAF outcomes, from the perspective of the player who made the move into the node
+func (node *NodeBase[T]) AmafOutcomes() Result {
+	return Result(node.amafOutcomes.Load()) / outcomeScale
+}
+
+func (node *NodeBase[T]) AddAmafOutcome(result Result) {
+	node.amafOutcomes.Add(uint64(result * outcomeScale))
+	node.amafVisits.Add(1)
+}
+
+// UCB1 with the RAVE estimate blended into the exploitation term:
+//
+// (1 - beta) * wins/visits + beta * amaf_wins/amaf_visits + C * sqrt(ln(parent_visits)/visits)
+//
+// where beta = sqrt(k / (3 * visits + k)), k being the equivalence parameter (SearchConfig.RaveEquivalence),
+// number of visits, at which the AMAF and regular statistics have the same weight
+func UCB1RAVE[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
+
+	// Same as in UCB1, terminal node has no children
+	if parent.Terminal() {
+		return parent
+	}
+
+	max := float64(-1)
+	index := 0
+	lnParentVisits := math.Log(float64(parent.Visits()))
+	var child *NodeBase[T]
+	var actualVisits, visits, vl int32
+
+	for i := 0; i < len(parent.Children); i++ {
+		child = &parent.Children[i]
+
+		// Skip the subtrees with known result
+		if child.Proven() {
+			continue
+		}
+
+		visits, vl = child.GetVvl()
+		actualVisits = visits - vl
+
+		// Pick the unvisited one, or score it with the first-play urgency
+		if actualVisits == 0 {
+			if cfg.FPU < 0 {
+				return child
+			}
+			if cfg.FPU > max {
+				max = cfg.FPU
+				index = i
+			}
+			continue
+		}
+
+		value := float64(child.Outcomes()) / float64(visits)
+		if amafVisits := child.AmafVisits(); amafVisits > 0 {
+			beta := math.Sqrt(cfg.RaveEquivalence / (3*float64(visits) + cfg.RaveEquivalence))
+			amaf := float64(child.AmafOutcomes()) / float64(amafVisits)
+			value = (1-beta)*value + beta*amaf
+		}
+
+		ucb := value + cfg.Exploration*math.Sqrt(lnParentVisits/float64(visits))
+		if ucb > max {
+			max = ucb
+			index = i
+		}
+	}
+
+	return &parent.Children[index]
+}
+
+// Update the AMAF statistics along the path from the leaf 'node' to the root.
+// 'result' is the result of the simulation from the leaf's perspective (same as in
+// Backpropagate), 'rollout' are the moves played in the rollout
+func (mcts *MCTS[T]) backpropagateAmaf(node *NodeBase[T], result Result, rollout []T, scratch *raveScratch[T]) {
+	// Collect the path (leaf -> root)
+	scratch.path = scratch.path[:0]
+	for n := node; n != nil; n = n.Parent {
+		scratch.path = append(scratch.path, n)
+	}
+	mcts.backpropagateAmafPath(result, rollout, scratch)
+}
+
+// Same as backpropagateAmaf, but uses the path (leaf -> root) already stored in the scratch
+func (mcts *MCTS[T]) backpropagateAmafPath(result Result, rollout []T, scratch *raveScratch[T]) {
+	// Build the sequence of moves played after the root
+
+	leafDepth := len(scratch.path) - 1
+	scratch.sequence = scratch.sequence[:0]
+	for i := leafDepth - 1; i >= 0; i-- {
+		scratch.sequence = append(scratch.sequence, scratch.path[i].NodeSignature)
+	}
+	scratch.sequence = append(scratch.sequence, rollout...)
+
+	clear(scratch.played[0])
+	clear(scratch.played[1])
+	for i := leafDepth; i < len(scratch.sequence); i++ {
+		scratch.played[i%2][scratch.sequence[i]] = struct{}{}
+	}
+
+	// Go up the tree, the player to move at depth 'd' plays moves at the indexes d, d+2, ...
+	for depth := leafDepth; depth >= 0; depth-- {
+		if depth < leafDepth {
+			scratch.played[depth%2][scratch.sequence[depth]] = struct{}{}
+		}
+
+		parent := scratch.path[leafDepth-depth]
+		if !parent.Expanded() {
+			continue
+		}
+
+		// Children outcomes are from the perspective of the player to move at 'depth'
+		outcome := result
+		if (leafDepth-depth-1)%2 == 0 {
+			outcome = 1 - result
+		}
+
+		played := scratch.played[depth%2]
+		for i := range parent.Children {
+			child := &parent.Children[i]
+			if _, ok := played[child.NodeSignature]; ok {
+				child.AddAmafOutcome(outcome)
+			}
+		}
+	}
+}
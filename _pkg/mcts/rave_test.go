@@ -0,0 +1,86 @@
+package mcts
+
+import "testing"
+
+// Add children with given signatures to the node, and mark it as expanded
+func expandTestNode(node *NodeBase[int], children ...int) *NodeBase[int] {
+	node.Children = make([]NodeBase[int], len(children))
+	for i, c := range children {
+		node.Children[i] = *NewBaseNode(node, c, false)
+	}
+	node.CanExpand()
+	node.FinishExpanding()
+	return node
+}
+
+// Add the outcomes summing up to 'sum', as wins and a draw for the remaining half
+func addTestOutcomes[T MoveLike](node *NodeBase[T], sum Result) {
+	for range int(sum) {
+		node.AddOutcome(1)
+	}
+	if rest := sum - Result(int(sum)); rest > 0 {
+		node.AddOutcome(rest)
+	}
+}
+
+func TestRaveAmafBackpropagation(t *testing.T) {
+	// root -> {1, 2, 3}, 1 -> {4, 5}, the path is root -> 1 -> 4,
+	// then the rollout plays 2, 5, 3 so the sequence is [1 4 2 5 3]
+	tree := &MCTS[int]{}
+	root := expandTestNode(&NodeBase[int]{}, 1, 2, 3)
+	first := expandTestNode(&root.Children[0], 4, 5)
+	leaf := &first.Children[0]
+
+	// Leaf's side to move won the simulation
+	tree.backpropagateAmaf(leaf, 1.0, []int{2, 5, 3}, newRaveScratch[int]())
+
+	// Root's player played 1, 2 and 3 (the same player, who is to move in the leaf)
+	for i := range root.Children {
+		child := &root.Children[i]
+		if child.AmafVisits() != 1 || child.AmafOutcomes() != 1.0 {
+			t.Errorf("Root child %d: amaf visits=%d outcomes=%f, want=1, 1.0",
+				child.NodeSignature, child.AmafVisits(), child.AmafOutcomes())
+		}
+	}
+
+	// Opponent played 4 and 5, and lost
+	for i := range first.Children {
+		child := &first.Children[i]
+		if child.AmafVisits() != 1 || child.AmafOutcomes() != 0.0 {
+			t.Errorf("Child %d: amaf visits=%d outcomes=%f, want=1, 0.0",
+				child.NodeSignature, child.AmafVisits(), child.AmafOutcomes())
+		}
+	}
+
+	// Moves played by the other player shouldn't be counted
+	tree.backpropagateAmaf(leaf, 0.0, []int{5, 2}, newRaveScratch[int]())
+	if v := root.Children[1].AmafVisits(); v != 1 {
+		t.Errorf("Move 2 played by the opponent, amaf visits=%d, want=1", v)
+	}
+	if v := root.Children[2].AmafVisits(); v != 1 {
+		t.Errorf("Move 3 wasn't played, amaf visits=%d, want=1", v)
+	}
+	if v := first.Children[1].AmafVisits(); v != 1 {
+		t.Errorf("Move 5 played by the root's player, amaf visits=%d, want=1", v)
+	}
+}
+
+func TestUCB1RAVESelection(t *testing.T) {
+	parent := expandTestNode(&NodeBase[int]{}, 1, 2)
+	parent.SetVvl(20, 0)
+
+	// Same regular statistics, but the second child has better AMAF value
+	for i := range parent.Children {
+		parent.Children[i].SetVvl(10, 0)
+		addTestOutcomes(&parent.Children[i], 5)
+	}
+	for range 50 {
+		parent.Children[0].AddAmafOutcome(0.2)
+		parent.Children[1].AddAmafOutcome(0.8)
+	}
+
+	cfg := DefaultSearchConfig()
+	if selected := UCB1RAVE(parent, parent, &cfg); selected.NodeSignature != 2 {
+		t.Errorf("Selected=%d, want=2", selected.NodeSignature)
+	}
+}
@@ -0,0 +1,119 @@
+package mcts
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Root parallelization, each thread (except the main one) searches its own private tree,
+// built from a clone of the game operations. When the search stops, the statistics of
+// the private root's children are merged into the main tree
+
+type ParallelMode int
+
+const (
+	// All threads search the same tree, using the virtual loss
+	TreeParallel ParallelMode = iota
+	// Each thread searches its own tree, the root's statistics are merged at the end
+	RootParallel
+)
+
+// State shared by the root-parallel search threads
+type rootParallelState struct {
+	cycles atomic.Int32 // cycles of the private trees, not yet merged into the main tree
+	size   atomic.Int32 // combined size of the private trees
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// Number of cycles done in this search, including the root-parallel threads
+func (mcts *MCTS[T]) cycles() uint32 {
+	return uint32(mcts.Root.Visits() + mcts.parallel.cycles.Load())
+}
+
+// Size of the tree, including the root-parallel private trees
+func (mcts *MCTS[T]) totalSize() uint32 {
+	return uint32(int32(mcts.Size()) + mcts.parallel.size.Load())
+}
+
+// Create a private tree with the same root position and search settings, it
+// shares the limiter with this tree
+func (mcts *MCTS[T]) privateTree(ops GameOperations[T]) *MCTS[T] {
+	tree := NewMTCS(mcts.policy, ops, atomic.LoadUint32(&mcts.Root.Flags)&TerminalMask)
+	tree.Limiter = mcts.Limiter
+	tree.solver = mcts.solver
+	tree.evalMix = mcts.evalMix
+	tree.rave = mcts.rave
+	tree.search = mcts.search
+	tree.setPriors(tree.evaluator(ops), tree.Root)
+	return tree
+}
+
+// Root-parallel search thread, searches its private tree, and merges the
+// root's statistics into this tree, when the search is over
+func (mcts *MCTS[T]) searchPrivate(ops GameOperations[T], threadId int) {
+	defer mcts.wg.Done()
+	defer mcts.threads.Add(-1)
+	defer mcts.parallel.wg.Done()
+
+	threadRand := mcts.NewRand(threadId)
+
+	if mcts.Root.Terminal() {
+		return
+	}
+
+	tree := mcts.privateTree(ops)
+	mcts.parallel.size.Add(int32(tree.Size()))
+
+	tree.searchTree(mcts, ops, threadRand, threadId)
+	mcts.Limiter.SetStop(true)
+	mcts.mergeRoot(tree)
+}
+
+// Add the statistics of the private tree's root (and its children) to this tree
+func (mcts *MCTS[T]) mergeRoot(tree *MCTS[T]) {
+	mcts.parallel.mu.Lock()
+	defer mcts.parallel.mu.Unlock()
+
+	// The main thread might still be pruning this tree
+	if mcts.arena != nil {
+		mcts.pruneMu.RLock()
+		defer mcts.pruneMu.RUnlock()
+	}
+
+	for i := range tree.Root.Children {
+		child := &tree.Root.Children[i]
+		target := findChild(mcts.Root, child.NodeSignature)
+		if target == nil {
+			continue
+		}
+
+		target.AddVvl(child.RealVisits(), 0)
+		target.mergeWDL(&child.NodeStats)
+		target.amafOutcomes.Add(child.amafOutcomes.Load())
+		target.amafVisits.Add(child.amafVisits.Load())
+
+		// Proven results are exact, no matter which tree found them
+		if flags := atomic.LoadUint32(&child.Flags) & ProvenMask; flags != 0 && target.setProven(flags) {
+			mcts.backupProof(mcts.Root)
+		}
+	}
+
+	visits := tree.Root.RealVisits()
+	mcts.Root.AddVvl(visits, 0)
+	mcts.Root.mergeWDL(&tree.Root.NodeStats)
+
+	// Now those are counted in the main tree
+	mcts.parallel.cycles.Add(-visits)
+	mcts.parallel.size.Add(-int32(tree.Size()))
+}
+
+// Find the child with given signature, nil if there is none
+func findChild[T MoveLike](node *NodeBase[T], signature T) *NodeBase[T] {
+	for i := range node.Children {
+		if node.Children[i].NodeSignature == signature {
+			return &node.Children[i]
+		}
+	}
+	return nil
+}
@@ -1,14 +1,14 @@
 package mcts
 
 import (
+	"context"
 	"math"
 	"math/rand"
-	"runtime"
-	"time"
+	"slices"
 )
 
 // Default node selection policy (upper confidence bound)
-func UCB1[T MoveLike](parent, root *NodeBase[T]) *NodeBase[T] {
+func UCB1[T MoveLike](parent, root *NodeBase[T], cfg *SearchConfig) *NodeBase[T] {
 
 	// Is that's a terminal node, simply return itself, there is no children anyway
 	// and on the rollout we will exit early, since the position is terminated
@@ -27,13 +27,26 @@ func UCB1[T MoveLike](parent, root *NodeBase[T]) *NodeBase[T] {
 
 		// Get the variables
 		child = &parent.Children[i]
+
+		// Skip the subtrees with known result
+		if child.Proven() {
+			continue
+		}
+
 		visits, vl = child.GetVvl()
 		actualVisits = visits - vl
 
-		// Pick the unvisited one
+		// Pick the unvisited one, or score it with the first-play urgency
 		if actualVisits == 0 {
-			// Return pointer to the child
-			return child
+			if cfg.FPU < 0 {
+				// Return pointer to the child
+				return child
+			}
+			if cfg.FPU > max {
+				max = cfg.FPU
+				index = i
+			}
+			continue
 		}
 
 		wins = child.Outcomes()
@@ -43,7 +56,7 @@ func UCB1[T MoveLike](parent, root *NodeBase[T]) *NodeBase[T] {
 		// Since we assume the game is zero-sum, we want to expand the tree's nodes
 		// that have best value according to the root
 		ucb1 := float64(wins)/float64(visits) +
-			threadExploration*math.Sqrt(lnParentVisits/float64(visits))
+			cfg.Exploration*math.Sqrt(lnParentVisits/float64(visits))
 
 		if ucb1 > max {
 			max = ucb1
@@ -61,27 +74,55 @@ func (mcts *MCTS[T]) Synchronize() {
 
 // Run multi-treaded search, to wait for the result, call Synchronize
 func (mcts *MCTS[T]) SearchMultiThreaded(ops GameOperations[T]) {
-	mcts.setupSearch()
+	mcts.startSearch(context.Background(), ops)
+}
+
+// Run the search until it hits the limits, or the context is cancelled, blocks until
+// all of the search threads are done. The tree keeps the partial result after cancelling.
+// Unlike Stop, the cancellation applies only to this search, so a stale context
+// can't stop the next one
+func (mcts *MCTS[T]) SearchContext(ctx context.Context, ops GameOperations[T]) {
+	mcts.startSearch(ctx, ops)
+	mcts.Synchronize()
+}
+
+func (mcts *MCTS[T]) startSearch(ctx context.Context, ops GameOperations[T]) {
+	mcts.setupSearch(ctx)
 	threads := max(1, mcts.Limiter.Limits().NThreads)
 
-	// if threads >= 8 {
-	// 	threadExploration = (0.75 + 1/float64(threads)) * ExplorationParam
-	// } else if threads >= 4 {
-	// 	threadExploration = (0.25 + math.Sqrt(float64(threads))/3) * ExplorationParam
-	// } else {
-	// 	threadExploration = ExplorationParam
-	// }
-	VirtualLoss = 2
+	// Root is expanded without the priors, set them before the search
+	if evaluator := mcts.evaluator(ops); evaluator != nil && mcts.Root.Expanded() {
+		mcts.setPriors(evaluator, mcts.Root)
+	}
+
+	// The search threads return immediately from the terminal root, without calling the listener
+	if !mcts.Root.Terminal() {
+		mcts.startListener()
+	}
+
+	// Each thread (except the main one) searches its own tree
+	mcts.threads.Add(int32(threads))
+	if mcts.Limiter.Limits().Parallel == RootParallel {
+		mcts.wg.Add(1)
+		go mcts.Search(mcts.threadOps(ops, 0), 0)
+
+		for id := 1; id < threads; id++ {
+			mcts.wg.Add(1)
+			mcts.parallel.wg.Add(1)
+			go mcts.searchPrivate(mcts.threadOps(ops, id), id)
+		}
+		return
+	}
 
 	for id := range threads {
 		mcts.wg.Add(1)
-		go mcts.Search(ops.Clone(), id)
+		go mcts.Search(mcts.threadOps(ops, id), id)
 	}
 }
 
 // This function only sets the limits, resets the counters, and the stop flag
 // doesn't actually start the search
-func (mcts *MCTS[T]) setupSearch() {
+func (mcts *MCTS[T]) setupSearch(ctx context.Context) {
 	// Setup
 	// mcts.timer.Movetime(mcts.Limiter.Limits.Movetime)
 	// mcts.timer.Reset()
@@ -89,6 +130,29 @@ func (mcts *MCTS[T]) setupSearch() {
 	mcts.nodes.Store(0)
 	mcts.cps.Store(0)
 	mcts.maxdepth.Store(0)
+	mcts.parallel.cycles.Store(0)
+	mcts.parallel.size.Store(0)
+	mcts.transpositions.Store(0)
+	mcts.collisionCount.Store(0)
+	mcts.done = ctx.Done()
+
+	// Use the same config for the whole search, even if it's changed in the meantime
+	mcts.search = mcts.config.forThreads(mcts.Limiter.Limits().NThreads)
+	mcts.setupRootPolicy(mcts.Limiter.Limits())
+
+	// Config may override the selection policy by its name
+	mcts.policy = mcts.selection_policy
+	if mcts.search.Policy != "" {
+		if policy, err := NewSelectionPolicy[T](mcts.search.Policy); err == nil {
+			mcts.policy = policy
+		}
+	}
+
+	// Allocate the time for this move
+	mcts.clock = nil
+	if limits := mcts.Limiter.Limits(); limits.UseClock() {
+		mcts.clock = NewTimeManager[T](limits, &mcts.search)
+	}
 	// mcts.stop.Store(false)
 }
 
@@ -104,82 +168,236 @@ func (mcts *MCTS[T]) setupSearch() {
 // threadId must be unique, 0 meaning it's the main search threads with some privileges
 func (mcts *MCTS[T]) Search(ops GameOperations[T], threadId int) {
 	defer mcts.wg.Done()
+	defer mcts.threads.Add(-1)
 
-	threadRand := rand.New(rand.NewSource(time.Now().UnixNano() + int64(threadId)))
+	threadRand := mcts.NewRand(threadId)
 
 	if mcts.Root.Terminal() {
 		return
 	}
 
-	var node *NodeBase[T]
-
-	for mcts.Limiter.Ok(mcts.Nodes(), mcts.Size(), uint32(mcts.MaxDepth()), uint32(mcts.Root.Visits())) {
-
-		// Choose the most promising node
-		node = mcts.Selection(ops, threadRand, threadId)
-		// Get the result of the rollout/playout
-		mcts.Backpropagate(ops, node, ops.Rollout())
-
-		// Store the cps
-		mcts.cps.Store(uint32(mcts.Root.Visits()) * 1000 / mcts.Limiter.Elapsed())
-		mcts.invokeListener(mcts.listener.onCycle)
-	}
+	mcts.searchTree(mcts, ops, threadRand, threadId)
 
 	// Synchronize all threads
-	mcts.Limiter.Stop()
+	mcts.Limiter.SetStop(true)
 
 	// Make sure only 1 thread calls this
 	if threadId == 0 {
+		// Wait for the root-parallel threads to merge their results
+		mcts.parallel.wg.Wait()
+		mcts.stopListener()
 		mcts.invokeListener(mcts.listener.onStop)
 	}
 }
 
+// Main search loop, runs the search cycles on 'tree', with the limits and counters
+// taken from the 'main' tree (those are the same tree, unless that's a root-parallel search)
+func (tree *MCTS[T]) searchTree(main *MCTS[T], ops GameOperations[T], threadRand *rand.Rand, threadId int) {
+	var node *NodeBase[T]
+	var result Result
+	var rolledOut bool
+	evaluator := tree.evaluator(ops)
+	var eval leafEval[T]
+
+	// Setup the RAVE, if the game supports it
+	recorder, rave := ops.(RolloutRecorder[T])
+	rave = rave && tree.rave
+	var scratch *raveScratch[T]
+	if rave {
+		scratch = newRaveScratch[T]()
+	}
+
+	private := tree != main
+	lastSize := tree.Size()
+	arena := tree.arena != nil
+	dag := tree.dag
+	path := make([]*NodeBase[T], 0, 64)
+
+	for main.Limiter.Ok(main.Nodes(), main.totalSize(), uint32(main.MaxDepth()), main.cycles()) {
+
+		// The search's context was cancelled
+		select {
+		case <-main.done:
+			return
+		default:
+		}
+
+		// The result of the position is known, there is nothing more to search
+		if tree.solver && tree.Root.Proven() {
+			break
+		}
+
+		// Main thread prunes the tree, while the other ones wait between the cycles
+		if arena {
+			if threadId == 0 && tree.needsPruning() {
+				tree.pruneMu.Lock()
+				tree.prune(uint32(arenaPruneTarget * float64(tree.maxTreeSize())))
+				tree.pruneMu.Unlock()
+			}
+			tree.pruneMu.RLock()
+		}
+
+		// Choose the most promising node, in the DAG mode remember the path, since
+		// the nodes' parents might be on the other one
+		if dag {
+			node, path = tree.selection(ops, threadRand, threadId, path[:0], &eval)
+		} else {
+			node, _ = tree.selection(ops, threadRand, threadId, nil, &eval)
+		}
+		// Get the result of the rollout/playout (or the evaluation)
+		result, rolledOut = tree.leafValue(ops, evaluator, node, &eval)
+		if rave {
+			var rollout []T
+			if rolledOut {
+				rollout = recorder.RolloutMoves()
+			}
+			if dag {
+				scratch.path = append(scratch.path[:0], path...)
+				slices.Reverse(scratch.path)
+				tree.backpropagateAmafPath(result, rollout, scratch)
+			} else {
+				tree.backpropagateAmaf(node, result, rollout, scratch)
+			}
+		}
+		if dag {
+			tree.backpropagatePath(ops, path, result)
+		} else {
+			tree.Backpropagate(ops, node, result)
+		}
+		if threadId == 0 && main.listener.onBestMoveChange != nil {
+			main.checkBestMove()
+		}
+
+		// Update the main tree's counters
+		if private {
+			size := tree.Size()
+			main.nodes.Add(tree.nodes.Swap(0))
+			main.parallel.cycles.Add(1)
+			main.parallel.size.Add(int32(size - lastSize))
+			lastSize = size
+		}
+
+		// Store the cps, the listener reads the main tree, so it can't be pruned in the meantime
+		// (the private threads don't hold its lock)
+		main.cps.Store(main.cycles() * 1000 / main.Limiter.Elapsed())
+		if private && main.arena != nil {
+			main.pruneMu.RLock()
+		}
+		main.invokeListener(main.listener.onCycle)
+		if private && main.arena != nil {
+			main.pruneMu.RUnlock()
+		}
+		if arena {
+			tree.pruneMu.RUnlock()
+		}
+
+		// Main thread manages the time, in the root-parallel search the main tree's
+		// visits don't include the other threads, so only the deadlines are used
+		if threadId == 0 && main.clock != nil {
+			cps := main.Cps()
+			if main.Limits().Parallel == RootParallel {
+				cps = 0
+			}
+			if main.clock.ShouldStop(main.Root, main.Limiter.Elapsed(), cps) {
+				main.Limiter.SetStop(true)
+			}
+		}
+	}
+}
+
 // Selects next child to expand, by user-defined selection policy
 func (mcts *MCTS[T]) Selection(ops GameOperations[T], threadRand *rand.Rand, threadId int) *NodeBase[T] {
+	node, _ := mcts.selection(ops, threadRand, threadId, nil, nil)
+	return node
+}
 
-	node := mcts.Root
-	depth := 0
-	for node.Expanded() {
-		node = mcts.selection_policy(node, mcts.Root)
-		ops.Traverse(node.NodeSignature)
-		depth++
-		mcts.nodes.Add(1)
+// Number of times the thread backs off from a node expanded by the other thread, before it
+// gives up and evaluates that node as a leaf (without waiting for its children)
+const maxCollisionRetries = 3
 
-		// Apply virtual loss
-		node.AddVvl(VirtualLoss, VirtualLoss)
-	}
+// Selection implementation, if 'path' isn't nil, appends the visited nodes to it
+// (starting with the root), returns the leaf and the path. With the evaluator, the node
+// expanded by this thread is the leaf, and its value is stored in 'eval' (if it isn't nil)
+func (mcts *MCTS[T]) selection(ops GameOperations[T], threadRand *rand.Rand, threadId int, path []*NodeBase[T], eval *leafEval[T]) (*NodeBase[T], []*NodeBase[T]) {
+	var node *NodeBase[T]
+	var depth int
+	var backedOff [maxCollisionRetries]*NodeBase[T]
+	record := path != nil
+	retries := 0
+
+	for {
+		node, depth, path = mcts.descend(ops, path, record)
+
+		// Add new children to this node, after finding leaf node
+		if node.RealVisits() == 0 || node.Terminal() {
+			break
+		}
 
-	// Add new children to this node, after finding leaf node
-	if node.RealVisits() > 0 && !node.Terminal() {
 		// Expand the node, only if needed (expand flag is 0)
 		if mcts.Limiter.Expand() && node.CanExpand() {
-			mcts.size.Add(ops.ExpandNode(node))
+			evaluated := false
+			// In the DAG mode, reuse the children of the transposition
+			if !mcts.shareTransposition(ops, node) {
+				mcts.size.Add(mcts.expandNode(ops, node))
+				if evaluator := mcts.evaluator(ops); evaluator != nil {
+					value := mcts.setPriors(evaluator, node)
+					if eval != nil {
+						eval.node, eval.value = node, value
+					}
+					evaluated = true
+				}
+			}
 			// Now update it's state
 			node.FinishExpanding()
+			if evaluated {
+				break
+			}
 		}
 
-		// Currently expanding
-		first := true
-		for node.Expanding() {
-			if first {
-				// If this is the first time, increment the collision counter
-				mcts.collisionCount.Add(1)
-				first = false
+		// The other thread is expanding this node, instead of waiting for it, put extra
+		// virtual loss on the node (so it looks worse to this thread) and select again.
+		// After too many collisions, the node is simply evaluated as a leaf
+		if node.Expanding() {
+			mcts.collisionCount.Add(1)
+			if retries == maxCollisionRetries {
+				break
+			}
+
+			node.AddVvl(mcts.search.VirtualLoss, mcts.search.VirtualLoss)
+			backedOff[retries] = node
+			retries++
+			mcts.undoSelection(ops, node, path, depth)
+			if record {
+				path = path[:0]
 			}
-			runtime.Gosched()
+			continue
 		}
 
 		// Already set
 		if node.Expanded() {
-			// Select child at random
-			node = &node.Children[threadRand.Int31n(int32(len(node.Children)))]
+			if mcts.evalMix > 0 {
+				// Use the priors to choose the child
+				node = mcts.policy(node, mcts.Root, &mcts.search)
+			} else {
+				// Select child at random
+				node = &node.Children[threadRand.Int31n(int32(len(node.Children)))]
+			}
 			// Traverse to this child
 			ops.Traverse(node.NodeSignature)
 			depth++
 			mcts.nodes.Add(1)
+			if record {
+				path = append(path, node)
+			}
 			// Apply again virtual loss
-			node.AddVvl(VirtualLoss, VirtualLoss)
+			node.AddVvl(mcts.search.VirtualLoss, mcts.search.VirtualLoss)
 		}
+		break
+	}
+
+	// Remove the extra virtual loss of the collisions
+	for _, n := range backedOff[:retries] {
+		n.AddVvl(-mcts.search.VirtualLoss, -mcts.search.VirtualLoss)
 	}
 
 	// Set the 'max depth'
@@ -190,7 +408,45 @@ func (mcts *MCTS[T]) Selection(ops GameOperations[T], threadRand *rand.Rand, thr
 	}
 
 	// return the candidate
-	return node
+	return node, path
+}
+
+// Go down the tree from the root, choosing the children with the selection policy,
+// until a node without children is reached. Returns that node, its depth and the path
+func (mcts *MCTS[T]) descend(ops GameOperations[T], path []*NodeBase[T], record bool) (*NodeBase[T], int, []*NodeBase[T]) {
+	node := mcts.Root
+	if record {
+		path = append(path, node)
+	}
+
+	depth := 0
+	for node.Expanded() {
+		node = mcts.selectChild(node)
+		ops.Traverse(node.NodeSignature)
+		depth++
+		mcts.nodes.Add(1)
+		if record {
+			path = append(path, node)
+		}
+
+		// Apply virtual loss
+		node.AddVvl(mcts.search.VirtualLoss, mcts.search.VirtualLoss)
+	}
+	return node, depth, path
+}
+
+// Revert the descent to 'node' at given depth: remove the virtual loss
+// of the visited nodes, and undo the traversals
+func (mcts *MCTS[T]) undoSelection(ops GameOperations[T], node *NodeBase[T], path []*NodeBase[T], depth int) {
+	for i := depth; i > 0; i-- {
+		// In the DAG mode the parents might not be on the path
+		if len(path) > 0 {
+			node = path[i]
+		}
+		node.AddVvl(-mcts.search.VirtualLoss, -mcts.search.VirtualLoss)
+		ops.BackTraverse()
+		node = node.Parent
+	}
 }
 
 // Increment the counters (wins/visits) along the tree path
@@ -206,11 +462,16 @@ func (mcts *MCTS[T]) Backpropagate(ops GameOperations[T], node *NodeBase[T], res
 			which mirrors the goal of each player to maximize the value of their move.
 	*/
 
+	// Terminal node's result is exact, back it up the tree
+	if mcts.solver && node.Terminal() {
+		mcts.proveTerminal(node, 1.0-result)
+	}
+
 	for node != nil {
 
 		// Reverse virtual loss for non-root
 		if node.Parent != nil {
-			node.AddVvl(1-VirtualLoss, -VirtualLoss)
+			node.AddVvl(1-mcts.search.VirtualLoss, -mcts.search.VirtualLoss)
 		} else {
 			node.AddVvl(1, 0)
 		}
@@ -219,9 +480,11 @@ func (mcts *MCTS[T]) Backpropagate(ops GameOperations[T], node *NodeBase[T], res
 		// Add the outcome
 		node.AddOutcome(result)
 
-		// Backpropagate
+		// Backpropagate, the root's position is the one before the selection
+		if node.Parent != nil {
+			ops.BackTraverse()
+		}
 		node = node.Parent
-		ops.BackTraverse()
 		mcts.nodes.Add(1)
 	}
 }
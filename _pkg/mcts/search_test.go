@@ -0,0 +1,122 @@
+package mcts
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// Game operations expanding every node into two children (signature*10 + 1 and + 2),
+// counting the traversals
+type collisionTestOps struct {
+	depth int
+}
+
+func (ops *collisionTestOps) ExpandNode(parent *NodeBase[int]) uint32 {
+	expandTestNode(parent, parent.NodeSignature*10+1, parent.NodeSignature*10+2)
+	return 2
+}
+func (ops *collisionTestOps) Traverse(int)               { ops.depth++ }
+func (ops *collisionTestOps) BackTraverse()              { ops.depth-- }
+func (ops *collisionTestOps) Rollout() Result            { return 0.5 }
+func (ops *collisionTestOps) Reset()                     {}
+func (ops *collisionTestOps) Clone() GameOperations[int] { return ops }
+
+func TestSelectionCollision(t *testing.T) {
+	newTree := func(children ...int) *MCTS[int] {
+		tree := &MCTS[int]{
+			Root:             expandTestNode(&NodeBase[int]{}, children...),
+			Limiter:          NewLimiter(0),
+			selection_policy: UCB1[int],
+			policy:           UCB1[int],
+			search:           DefaultSearchConfig(),
+			listener:         &StatsListener[int]{},
+		}
+		tree.Root.SetVvl(20, 0)
+		return tree
+	}
+	random := rand.New(rand.NewSource(0))
+	vl := DefaultSearchConfig().VirtualLoss
+
+	t.Run("other path", func(t *testing.T) {
+		// First child looks better, but it's being expanded by the other thread
+		tree := newTree(1, 2)
+		busy, free := &tree.Root.Children[0], &tree.Root.Children[1]
+		busy.SetVvl(10, 0)
+		addTestOutcomes(busy, 5)
+		busy.SetFlag(ExpandingMask)
+		free.SetVvl(10, 0)
+		addTestOutcomes(free, 4)
+
+		ops := &collisionTestOps{}
+		node := tree.Selection(ops, random, 0)
+		if node.Parent != free || ops.depth != 2 {
+			t.Errorf("Selected %d at depth %d, want a child of %d", node.NodeSignature, ops.depth, free.NodeSignature)
+		}
+		if busy.VirtualLoss() != 0 || free.VirtualLoss() != vl {
+			t.Errorf("Virtual loss busy=%d free=%d, want=0, %d", busy.VirtualLoss(), free.VirtualLoss(), vl)
+		}
+		if tree.CollisionCount() != 1 {
+			t.Errorf("Collisions=%d, want=1", tree.CollisionCount())
+		}
+	})
+
+	t.Run("no other path", func(t *testing.T) {
+		// Evaluated as a leaf, after running out of the retries
+		tree := newTree(1)
+		busy := &tree.Root.Children[0]
+		busy.SetVvl(10, 0)
+		busy.SetFlag(ExpandingMask)
+
+		ops := &collisionTestOps{}
+		node := tree.Selection(ops, random, 0)
+		if node != busy || ops.depth != 1 {
+			t.Errorf("Selected %d at depth %d, want %d at depth 1", node.NodeSignature, ops.depth, busy.NodeSignature)
+		}
+		if busy.VirtualLoss() != vl || busy.RealVisits() != 10 {
+			t.Errorf("Virtual loss=%d real visits=%d, want=%d, 10", busy.VirtualLoss(), busy.RealVisits(), vl)
+		}
+		if tree.CollisionCount() != maxCollisionRetries+1 {
+			t.Errorf("Collisions=%d, want=%d", tree.CollisionCount(), maxCollisionRetries+1)
+		}
+
+		// Backpropagation clears the virtual loss
+		tree.Backpropagate(ops, node, 0.5)
+		if busy.VirtualLoss() != 0 || busy.Visits() != 11 || ops.depth != 0 {
+			t.Errorf("After backpropagation: virtual loss=%d visits=%d depth=%d, want=0, 11, 0",
+				busy.VirtualLoss(), busy.Visits(), ops.depth)
+		}
+	})
+}
+
+// Collision test game with the evaluator, counting the evaluations
+type evalTestOps struct {
+	collisionTestOps
+	evaluations int
+}
+
+func (ops *evalTestOps) Evaluate(node *NodeBase[int]) (Result, []float32) {
+	ops.evaluations++
+	priors := make([]float32, len(node.Children))
+	for i := range priors {
+		priors[i] = 1 / float32(len(priors))
+	}
+	return 0.5, priors
+}
+func (ops *evalTestOps) Clone() GameOperations[int] { return ops }
+
+func TestEvaluationPerCycle(t *testing.T) {
+	ops := &evalTestOps{}
+	tree := NewMTCS(UCB1[int], GameOperations[int](ops), 0)
+	tree.SetEvalMix(1)
+	tree.SetLimits(DefaultLimits().SetCycles(500))
+	tree.SearchContext(context.Background(), ops)
+
+	// Every cycle evaluates one node (the expanded ones only once), and the root gets its priors
+	if visits := int(tree.Root.Visits()); visits != 500 || ops.evaluations != visits+1 {
+		t.Errorf("Evaluations=%d after %d cycles, want=%d", ops.evaluations, visits, visits+1)
+	}
+	if ops.depth != 0 {
+		t.Errorf("Depth=%d after the search, want=0", ops.depth)
+	}
+}
@@ -0,0 +1,43 @@
+package mcts
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Seeding of the search, with Limits.Seed set, every thread's RNG (and its game
+// operations' RNG) is derived from that seed, so the same search gives the same result
+
+// Optional interface of the GameOperations, if the game uses randomness (for example
+// in the rollouts), it should implement it to make the seeded search reproducible
+type Seeder interface {
+	// Reseed the random number generator
+	Seed(seed int64)
+}
+
+// Derive the seed of given stream from the base seed (splitmix64)
+func deriveSeed(seed int64, stream int) int64 {
+	z := uint64(seed) + uint64(stream+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Get the random number generator for the search thread
+func (mcts *MCTS[T]) NewRand(threadId int) *rand.Rand {
+	if seed := mcts.Limits().Seed; seed >= 0 {
+		return rand.New(rand.NewSource(deriveSeed(seed, 2*threadId)))
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano() + int64(threadId)))
+}
+
+// Clone the game operations for the search thread, reseeded if the search is seeded
+func (mcts *MCTS[T]) threadOps(ops GameOperations[T], threadId int) GameOperations[T] {
+	clone := ops.Clone()
+	if seed := mcts.Limits().Seed; seed >= 0 {
+		if seeder, ok := clone.(Seeder); ok {
+			seeder.Seed(deriveSeed(seed, 2*threadId+1))
+		}
+	}
+	return clone
+}
@@ -0,0 +1,34 @@
+package mcts
+
+import "testing"
+
+func TestSeededRand(t *testing.T) {
+	tree := &MCTS[int]{Limiter: NewLimiter(0)}
+	tree.Limits().SetSeed(42)
+
+	// Same thread gets the same sequence, the other threads a different one
+	a, b, other := tree.NewRand(0), tree.NewRand(0), tree.NewRand(1)
+	same, differs := true, false
+	for range 16 {
+		x, y, z := a.Int63(), b.Int63(), other.Int63()
+		same = same && x == y
+		differs = differs || x != z
+	}
+	if !same || !differs {
+		t.Errorf("same=%v, differs=%v, want=true, true", same, differs)
+	}
+
+	// Game operations' streams don't overlap with the threads' ones
+	seen := make(map[int64]bool)
+	for stream := range 64 {
+		seed := deriveSeed(42, stream)
+		if seen[seed] {
+			t.Fatalf("Stream %d repeats the seed %d", stream, seed)
+		}
+		seen[seed] = true
+	}
+
+	if DefaultLimits().SetSeed(-5).Seed != DefaultSeed {
+		t.Error("Negative seed should mean seeding with the time")
+	}
+}
@@ -0,0 +1,264 @@
+package mcts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+)
+
+// Binary format of the saved search tree (little endian):
+//
+// header: magic "MCTS", version (uint16), signature size (uint16),
+// notation length (uint16), root position notation, number of nodes (uint32)
+//
+// nodes, in pre-order: signature, visits (int32), wins, draws and losses (uint64 each,
+// 10^-3 precision), flags (uint32), number of children (uint32)
+//
+// AMAF statistics and priors aren't saved, they are rebuilt by the next search
+
+const TreeFormatVersion uint16 = 2
+
+var treeMagic = [4]byte{'M', 'C', 'T', 'S'}
+
+// Maximum number of nodes read by ReadTree, if the size of the reader isn't known
+const MaxTreeNodes = 1 << 24
+
+// Header of the saved tree
+type TreeHeader struct {
+	Version  uint16
+	Notation string // root position, set by the game implementation
+	Size     uint32 // number of nodes in the tree
+}
+
+// Helper writer, remembering the first error and the number of written bytes
+type treeWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (tw *treeWriter) write(data any) {
+	if tw.err != nil {
+		return
+	}
+	if tw.err = binary.Write(tw.w, binary.LittleEndian, data); tw.err == nil {
+		tw.n += int64(binary.Size(data))
+	}
+}
+
+// Save the tree to 'w', with given root position notation in the header,
+// stops the running search. The signature type must have a fixed size (see encoding/binary).
+// The DAG mode isn't supported, the format has no way to store the shared children
+func (mcts *MCTS[T]) WriteTree(w io.Writer, notation string) (int64, error) {
+	if mcts.dag {
+		return 0, fmt.Errorf("mcts: can't save the tree in the DAG mode")
+	}
+
+	var signature T
+	sigSize := binary.Size(signature)
+	if sigSize <= 0 {
+		return 0, fmt.Errorf("mcts: node signature of type %T can't be serialized", signature)
+	}
+	if len(notation) > 0xffff {
+		return 0, fmt.Errorf("mcts: notation too long (%d bytes)", len(notation))
+	}
+
+	// Discard running search
+	if mcts.IsThinking() {
+		mcts.Stop()
+		mcts.Synchronize()
+	}
+
+	tw := &treeWriter{w: bufio.NewWriter(w)}
+	tw.write(treeMagic)
+	tw.write(TreeFormatVersion)
+	tw.write(uint16(sigSize))
+	tw.write(uint16(len(notation)))
+	tw.write([]byte(notation))
+	tw.write(uint32(countTreeNodes(mcts.Root, nil)))
+	writeNode(tw, mcts.Root)
+
+	if tw.err == nil {
+		tw.err = tw.w.Flush()
+	}
+	return tw.n, tw.err
+}
+
+// Save the tree without the root position, implements io.WriterTo
+func (mcts *MCTS[T]) WriteTo(w io.Writer) (int64, error) {
+	return mcts.WriteTree(w, "")
+}
+
+func writeNode[T MoveLike](tw *treeWriter, node *NodeBase[T]) {
+	tw.write(node.NodeSignature)
+	tw.write(node.RealVisits())
+	tw.write(node.wins.Load())
+	tw.write(node.draws.Load())
+	tw.write(node.losses.Load())
+	// Expanding flag is only valid during the search
+	tw.write(atomic.LoadUint32(&node.Flags) &^ ExpandingMask)
+	tw.write(uint32(len(node.Children)))
+
+	for i := range node.Children {
+		if tw.err != nil {
+			return
+		}
+		writeNode(tw, &node.Children[i])
+	}
+}
+
+// Read the tree saved with WriteTree, returns the root node and the header
+func ReadTree[T MoveLike](r io.Reader) (*NodeBase[T], TreeHeader, error) {
+	var header TreeHeader
+	var magic [4]byte
+	var sigSize, notationLen uint16
+	br := bufio.NewReader(r)
+
+	read := func(data any) error {
+		return binary.Read(br, binary.LittleEndian, data)
+	}
+
+	if err := read(&magic); err != nil {
+		return nil, header, err
+	}
+	if magic != treeMagic {
+		return nil, header, fmt.Errorf("mcts: invalid tree file, bad magic %q", magic[:])
+	}
+	if err := read(&header.Version); err != nil {
+		return nil, header, err
+	}
+	if header.Version != TreeFormatVersion {
+		return nil, header, fmt.Errorf("mcts: unsupported tree format version %d, expected %d", header.Version, TreeFormatVersion)
+	}
+
+	var signature T
+	if err := read(&sigSize); err != nil {
+		return nil, header, err
+	}
+	if int(sigSize) != binary.Size(signature) {
+		return nil, header, fmt.Errorf("mcts: signature size mismatch, file has %d, %T has %d", sigSize, signature, binary.Size(signature))
+	}
+
+	if err := read(&notationLen); err != nil {
+		return nil, header, err
+	}
+	notation := make([]byte, notationLen)
+	if _, err := io.ReadFull(br, notation); err != nil {
+		return nil, header, err
+	}
+	header.Notation = string(notation)
+	if err := read(&header.Size); err != nil {
+		return nil, header, err
+	}
+
+	// Reject the size not matching the data, before allocating the nodes
+	limit := int64(MaxTreeNodes)
+	if size, ok := readerSize(r); ok {
+		limit = min(limit, size/nodeRecordSize(int64(sigSize)))
+	}
+	if int64(header.Size) > limit {
+		return nil, header, fmt.Errorf("mcts: tree has %d nodes, expected at most %d", header.Size, limit)
+	}
+
+	root := &NodeBase[T]{}
+	remaining := header.Size
+	if err := readNode(read, root, &remaining); err != nil {
+		return nil, header, err
+	}
+	if remaining != 0 {
+		return nil, header, fmt.Errorf("mcts: expected %d nodes, read %d", header.Size, header.Size-remaining)
+	}
+	return root, header, nil
+}
+
+// Size of the node in the file, with given signature size
+func nodeRecordSize(sigSize int64) int64 {
+	// signature, visits, win/draw/loss counters, flags, number of children
+	return sigSize + 4 + 3*8 + 4 + 4
+}
+
+// Size of the reader's data, if it's known (for example bytes.Reader or a regular file)
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case *bytes.Reader:
+		return r.Size(), true
+	case *strings.Reader:
+		return r.Size(), true
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size(), true
+		}
+	}
+	return 0, false
+}
+
+func readNode[T MoveLike](read func(any) error, node *NodeBase[T], remaining *uint32) error {
+	var visits int32
+	var wdl [3]uint64
+	var childCount uint32
+
+	if *remaining == 0 {
+		return fmt.Errorf("mcts: tree has more nodes than declared in the header")
+	}
+	*remaining--
+
+	for _, data := range []any{&node.NodeSignature, &visits, &wdl, &node.Flags, &childCount} {
+		if err := read(data); err != nil {
+			return err
+		}
+	}
+	// Same invariants as in the search: the expanded nodes have the children, the terminal ones
+	// don't, and the expanding flag is only valid during the search (see writeNode)
+	node.Flags &^= ExpandingMask
+	expanded, terminal := node.Flags&ExpandedMask != 0, node.Flags&TerminalMask != 0
+	if visits < 0 || childCount > *remaining || expanded != (childCount > 0) || (terminal && childCount > 0) {
+		return fmt.Errorf("mcts: corrupted node (visits=%d, children=%d, flags=%d)", visits, childCount, node.Flags)
+	}
+
+	node.SetVvl(visits, 0)
+	node.addWDL(wdl[0], wdl[1], wdl[2])
+	// The squared outcomes aren't stored, count them as if there were only wins, draws and losses
+	node.squares.Store(wdl[0] + wdl[1]/4)
+	if childCount == 0 {
+		return nil
+	}
+
+	node.Children = make([]NodeBase[T], childCount)
+	for i := range node.Children {
+		child := &node.Children[i]
+		child.Parent = node
+		if err := readNode(read, child, remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replace the tree with the given one (for example read with ReadTree), the game state
+// should be set to the root position beforehand, the same way as before calling Reset
+func (mcts *MCTS[T]) SetRoot(ops GameOperations[T], root *NodeBase[T]) {
+	// Discard running search
+	if mcts.IsThinking() {
+		mcts.Stop()
+		mcts.Synchronize()
+	}
+
+	ops.Reset()
+	if mcts.arena != nil {
+		mcts.arena.Reset()
+	}
+	root.Parent = nil
+	mcts.Root = root
+	mcts.size.Store(uint32(mcts.countNodes(root)))
+
+	// Same as in AdvanceRoot, the search expects expanded root
+	if !root.Terminal() && root.CanExpand() {
+		root.FinishExpanding()
+		mcts.size.Add(mcts.expandNode(ops, root))
+	}
+}
@@ -0,0 +1,113 @@
+package mcts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestTreeRoundTrip(t *testing.T) {
+	// root -> {1, 2}, 1 -> {3}
+	tree := &MCTS[int32]{Root: &NodeBase[int32]{}, Limiter: NewLimiter(0)}
+	root := tree.Root
+	root.Children = []NodeBase[int32]{*NewBaseNode(root, 1, false), *NewBaseNode(root, 2, true)}
+	root.SetFlag(ExpandedMask)
+	root.SetVvl(10, 0)
+	addTestOutcomes(root, 4.5)
+
+	first := &root.Children[0]
+	first.Children = []NodeBase[int32]{*NewBaseNode(first, 3, false)}
+	first.SetFlag(ExpandedMask)
+	first.SetVvl(6, 0)
+	addTestOutcomes(first, 2)
+	root.Children[1].SetFlag(TerminalMask | ProvenWinMask)
+	root.Children[1].SetVvl(4, 0)
+	addTestOutcomes(&root.Children[1], 4)
+
+	buffer := bytes.Buffer{}
+	n, err := tree.WriteTree(&buffer, "startpos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buffer.Len()) {
+		t.Errorf("Written bytes=%d, buffer has %d", n, buffer.Len())
+	}
+
+	loaded, header, err := ReadTree[int32](&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Notation != "startpos" || header.Size != 4 || header.Version != TreeFormatVersion {
+		t.Errorf("Invalid header %+v", header)
+	}
+
+	var compare func(a, b *NodeBase[int32])
+	compare = func(a, b *NodeBase[int32]) {
+		if a.NodeSignature != b.NodeSignature || a.Visits() != b.Visits() ||
+			a.Outcomes() != b.Outcomes() || a.WDL() != b.WDL() || a.Flags != b.Flags || len(a.Children) != len(b.Children) {
+			t.Errorf("Node mismatch: got (%d v=%d o=%f f=%d), want (%d v=%d o=%f f=%d)",
+				a.NodeSignature, a.Visits(), a.Outcomes(), a.Flags,
+				b.NodeSignature, b.Visits(), b.Outcomes(), b.Flags)
+			return
+		}
+		for i := range a.Children {
+			if a.Children[i].Parent != a {
+				t.Errorf("Invalid parent of node %d", a.Children[i].NodeSignature)
+			}
+			compare(&a.Children[i], &b.Children[i])
+		}
+	}
+	compare(loaded, root)
+}
+
+func TestReadTreeErrors(t *testing.T) {
+	tree := &MCTS[int32]{Root: &NodeBase[int32]{}, Limiter: NewLimiter(0)}
+	buffer := bytes.Buffer{}
+	if _, err := tree.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"bad magic", append([]byte("TREE"), data[4:]...)},
+		{"bad version", append(append([]byte{}, data[:4]...), append([]byte{99, 0}, data[6:]...)...)},
+		{"truncated", data[:len(data)-1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadTree[int32](bytes.NewReader(tt.data)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	// Node count not matching the data, or above the limit
+	oversized := func(size uint32) []byte {
+		corrupted := append([]byte{}, data...)
+		binary.LittleEndian.PutUint32(corrupted[len(data)-4-int(nodeRecordSize(4)):], size)
+		return corrupted
+	}
+	if _, _, err := ReadTree[int32](bytes.NewReader(oversized(1 << 30))); err == nil || !strings.Contains(err.Error(), "expected at most") {
+		t.Errorf("Expected the size error, got %v", err)
+	}
+	if _, _, err := ReadTree[int32](bufio.NewReader(bytes.NewReader(oversized(MaxTreeNodes + 1)))); err == nil || !strings.Contains(err.Error(), "expected at most") {
+		t.Errorf("Expected the size error for unknown reader's size, got %v", err)
+	}
+
+	// Signature type mismatch
+	if _, _, err := ReadTree[int64](bytes.NewReader(data)); err == nil {
+		t.Errorf("Expected signature size error")
+	}
+
+	// Not fixed-size signature
+	if _, err := (&MCTS[int]{Root: &NodeBase[int]{}, Limiter: NewLimiter(0)}).WriteTo(&buffer); err == nil {
+		t.Errorf("Expected an error for the 'int' signature")
+	}
+}
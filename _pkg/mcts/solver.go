@@ -0,0 +1,184 @@
+package mcts
+
+import "sync/atomic"
+
+// MCTS-Solver, backs up proven game results (wins, losses and draws) from the terminal
+// nodes up the tree. The proven flags are always set from the perspective of the player
+// who made the move into the node (the same as the node's outcomes)
+
+// Enable or disable the solver mode, the proven results are backed up
+// only if the solver is enabled
+func (mcts *MCTS[T]) SetSolver(solver bool) {
+	mcts.solver = solver
+}
+
+// Wheter the solver mode is enabled
+func (mcts *MCTS[T]) Solver() bool {
+	return mcts.solver
+}
+
+// Wheter the node's result is known (either a proven win, loss or draw)
+func (node *NodeBase[T]) Proven() bool {
+	return atomic.LoadUint32(&node.Flags)&ProvenMask != 0
+}
+
+// The player who made the move into this node wins
+func (node *NodeBase[T]) ProvenWin() bool {
+	return atomic.LoadUint32(&node.Flags)&ProvenWinMask == ProvenWinMask
+}
+
+// The player who made the move into this node loses
+func (node *NodeBase[T]) ProvenLoss() bool {
+	return atomic.LoadUint32(&node.Flags)&ProvenLossMask == ProvenLossMask
+}
+
+// The game ends in a draw from this node
+func (node *NodeBase[T]) ProvenDraw() bool {
+	return atomic.LoadUint32(&node.Flags)&ProvenDrawMask == ProvenDrawMask
+}
+
+// Atomically sets the proven flag, if the node wasn't proven yet,
+// returns true if this call set the flag
+func (node *NodeBase[T]) setProven(mask uint32) bool {
+	for {
+		flags := atomic.LoadUint32(&node.Flags)
+		if flags&ProvenMask != 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint32(&node.Flags, flags, flags|mask) {
+			return true
+		}
+	}
+}
+
+// Number of plies until the game ends, assuming both sides play the proven
+// line (the winner wins as fast as possible, the loser delays the end).
+// Returns 0 if the node isn't proven
+func (node *NodeBase[T]) ProofDistance() int {
+	if !node.Proven() {
+		return 0
+	}
+
+	if node.Terminal() || !node.Expanded() {
+		return 1
+	}
+
+	// Opponent (the side to move in this node) chooses the reply
+	distance := -1
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch {
+		case node.ProvenWin() && child.ProvenLoss():
+			// Opponent loses anyway, so he delays the end
+			distance = max(distance, child.ProofDistance())
+		case node.ProvenLoss() && child.ProvenWin(),
+			node.ProvenDraw() && child.ProvenDraw():
+			// Opponent takes the fastest win (or draw)
+			if d := child.ProofDistance(); distance == -1 || d < distance {
+				distance = d
+			}
+		}
+	}
+
+	return 1 + max(distance, 0)
+}
+
+// Mark the terminal node as proven, based on the outcome of the game (from the
+// perspective of the player who made the move into the node), and back up the result
+func (mcts *MCTS[T]) proveTerminal(node *NodeBase[T], outcome Result) {
+	if node.setProven(outcomeMask(outcome)) {
+		mcts.backupProof(node.Parent)
+	}
+}
+
+// Get the proven flag of the terminal node with given outcome
+func outcomeMask(outcome Result) uint32 {
+	if outcome >= 1 {
+		return ProvenWinMask
+	} else if outcome <= 0 {
+		return ProvenLossMask
+	}
+	return ProvenDrawMask
+}
+
+// Go up the tree starting from given node, marking the nodes as proven
+// until the result of the node can't be resolved
+func (mcts *MCTS[T]) backupProof(node *NodeBase[T]) {
+	for node != nil {
+		mask := resolveProof(node)
+		if mask == 0 || !node.setProven(mask) {
+			return
+		}
+		node = node.Parent
+	}
+}
+
+// Get the proven flag of the node, based on its children, returns 0 if the result is unknown:
+//
+// - if any child is a proven win (for the side to move), the node is a proven loss
+//
+// - if all children are resolved, and there is at least one draw, it's a proven draw
+//
+// - if all children are proven losses, the node is a proven win
+func resolveProof[T MoveLike](node *NodeBase[T]) uint32 {
+	if !node.Expanded() || len(node.Children) == 0 {
+		return 0
+	}
+
+	resolved := true
+	draw := false
+	for i := range node.Children {
+		flags := atomic.LoadUint32(&node.Children[i].Flags)
+		switch {
+		case flags&ProvenWinMask != 0:
+			return ProvenLossMask
+		case flags&ProvenDrawMask != 0:
+			draw = true
+		case flags&ProvenLossMask == 0:
+			resolved = false
+		}
+	}
+
+	if !resolved {
+		return 0
+	}
+	if draw {
+		return ProvenDrawMask
+	}
+	return ProvenWinMask
+}
+
+// Choose the best child based only on the proven results, returns (child, true)
+// if the choice is forced by them:
+//
+// - proven win with the shortest distance
+//
+// - if every child is a proven loss, the one with the longest distance
+func provenBestChild[T MoveLike](node *NodeBase[T]) (*NodeBase[T], bool) {
+	var win, loss *NodeBase[T]
+	winDistance, lossDistance := 0, 0
+	losses := 0
+
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch {
+		case child.ProvenWin():
+			if d := child.ProofDistance(); win == nil || d < winDistance {
+				win, winDistance = child, d
+			}
+		case child.ProvenLoss():
+			losses++
+			if d := child.ProofDistance(); loss == nil || d > lossDistance {
+				loss, lossDistance = child, d
+			}
+		}
+	}
+
+	if win != nil {
+		return win, true
+	}
+	if losses > 0 && losses == len(node.Children) {
+		return loss, true
+	}
+	return nil, false
+}
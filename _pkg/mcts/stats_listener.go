@@ -1,11 +1,19 @@
 package mcts
 
+import (
+	"sync"
+	"time"
+)
+
 type SearchLine[T MoveLike] struct {
 	BestMove T
 	Moves    []T
 	Eval     float64
+	WDL      WDL
 	Terminal bool
 	Draw     bool
+	// Number of plies until the game ends (exact if the line is proven by the solver)
+	MateDistance int
 }
 
 type ListenerTreeStats[T MoveLike] struct {
@@ -14,19 +22,24 @@ type ListenerTreeStats[T MoveLike] struct {
 	TimeMs   int
 	Cps      uint32
 	Lines    []SearchLine[T]
+	// Number of transpositions merged in the DAG mode
+	Transpositions int
 }
 
 // Convert TreeStats to 'ListenerTreeStats' struct
 func toListenerStats[T MoveLike](tree *MCTS[T]) ListenerTreeStats[T] {
-	pv := tree.MultiPv(BestChildMostVisits)
+	pv := tree.MultiPv(tree.search.BestChild)
 	lines := make([]SearchLine[T], len(pv))
 	for i := range len(pv) {
 		lines[i] = SearchLine[T]{
 			BestMove: pv[i].Root.NodeSignature,
 			Moves:    pv[i].Pv,
 			Eval:     float64(pv[i].Root.AvgOutcome()),
+			WDL:      pv[i].WDL(),
 			Terminal: pv[i].Terminal,
 			Draw:     pv[i].Draw,
+
+			MateDistance: pv[i].MateDistance,
 		}
 	}
 
@@ -36,6 +49,8 @@ func toListenerStats[T MoveLike](tree *MCTS[T]) ListenerTreeStats[T] {
 		Cycles:   int(tree.Root.Visits()),
 		TimeMs:   int(tree.Limiter.Elapsed()),
 		Cps:      tree.Cps(),
+
+		Transpositions: int(tree.Transpositions()),
 	}
 }
 
@@ -52,6 +67,13 @@ type StatsListener[T MoveLike] struct {
 
 	// called when the search stops (either by limiter or 'stop' signal)
 	onStop ListenerFunc[T]
+
+	// called every 'interval' during the search, by the search's ticker goroutine
+	onInterval ListenerFunc[T]
+	interval   time.Duration
+
+	// called by the main search thread, when the best move changes
+	onBestMoveChange ListenerFunc[T]
 }
 
 // Attach new on max depth change callback, will be called only be the main search thread
@@ -71,3 +93,79 @@ func (listener *StatsListener[T]) OnStop(onStop ListenerFunc[T]) *StatsListener[
 	listener.onStop = onStop
 	return listener
 }
+
+// Attach the callback called at a fixed cadence during the search, from a separate
+// goroutine (one per search). It's never called after the 'on stop' callback
+func (listener *StatsListener[T]) OnInterval(period time.Duration, onInterval ListenerFunc[T]) *StatsListener[T] {
+	listener.interval = period
+	listener.onInterval = onInterval
+	return listener
+}
+
+// Attach the callback called when the best move (chosen with the tree's best child policy) changes,
+// including the first one found in the search, will be called only by the main search thread
+func (listener *StatsListener[T]) OnBestMoveChange(onBestMoveChange ListenerFunc[T]) *StatsListener[T] {
+	listener.onBestMoveChange = onBestMoveChange
+	return listener
+}
+
+// Listener's state of the running search
+type listenerState[T MoveLike] struct {
+	done        chan struct{} // closed when the search ends, stops the ticker
+	wg          sync.WaitGroup
+	bestMove    T
+	hasBestMove bool
+}
+
+// Start the ticker goroutine of the interval callback, if it's set
+func (mcts *MCTS[T]) startListener() {
+	mcts.events.hasBestMove = false
+	listener := mcts.listener
+	if listener.onInterval == nil || listener.interval <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	mcts.events.done = done
+	mcts.events.wg.Add(1)
+	go func() {
+		defer mcts.events.wg.Done()
+		ticker := time.NewTicker(listener.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Don't read the tree while it's being pruned
+				if mcts.arena != nil {
+					mcts.pruneMu.RLock()
+				}
+				mcts.invokeListener(listener.onInterval)
+				if mcts.arena != nil {
+					mcts.pruneMu.RUnlock()
+				}
+			}
+		}
+	}()
+}
+
+// Stop the ticker goroutine, waits until the last interval callback returns
+func (mcts *MCTS[T]) stopListener() {
+	if mcts.events.done != nil {
+		close(mcts.events.done)
+		mcts.events.done = nil
+	}
+	mcts.events.wg.Wait()
+}
+
+// Invoke the 'best move change' callback, if the best move isn't the same as before
+func (mcts *MCTS[T]) checkBestMove() {
+	best := mcts.BestChild(mcts.Root, mcts.search.BestChild)
+	if best == nil || (mcts.events.hasBestMove && best.NodeSignature == mcts.events.bestMove) {
+		return
+	}
+	mcts.events.bestMove, mcts.events.hasBestMove = best.NodeSignature, true
+	mcts.invokeListener(mcts.listener.onBestMoveChange)
+}
@@ -0,0 +1,99 @@
+package mcts
+
+import "math"
+
+// Clock-based time management, gives each move a soft and a hard deadline. The search stops
+// at the soft deadline, unless the best move is unstable (then it's extended, up to the hard one),
+// or earlier, if the most visited child can't be overtaken before the deadline
+
+type TimeManager[T MoveLike] struct {
+	soft     uint32 // ms
+	hard     uint32 // ms
+	lastBest *NodeBase[T]
+	changes  int // number of the best move changes
+}
+
+// Allocate the time for the move, based on the clock of the side to move, with
+// the move overhead and the default moves-to-go taken from the config
+func NewTimeManager[T MoveLike](limits *Limits, cfg *SearchConfig) *TimeManager[T] {
+	left := max(1, limits.TimeLeft[limits.Side]-cfg.MoveOverhead)
+	inc := limits.Increment[limits.Side]
+	movesToGo := max(1, cfg.DefaultMovesToGo)
+	if limits.MovesToGo > 0 {
+		movesToGo = limits.MovesToGo
+	}
+
+	soft := min(left/movesToGo+inc*3/4, left)
+	hard := min(max(soft, min(5*soft, left/4+inc)), left)
+
+	return &TimeManager[T]{
+		soft: uint32(max(1, soft)),
+		hard: uint32(max(1, hard)),
+	}
+}
+
+// Soft deadline in ms
+func (tm *TimeManager[T]) Soft() uint32 {
+	return tm.soft
+}
+
+// Hard deadline in ms, the search never takes longer than this
+func (tm *TimeManager[T]) Hard() uint32 {
+	return tm.hard
+}
+
+// Decide if the search should stop, called by the main search thread. 'elapsed' is the
+// search time in ms, 'cps' the cycles per second, 0 disables the early stop
+func (tm *TimeManager[T]) ShouldStop(root *NodeBase[T], elapsed, cps uint32) bool {
+	if elapsed >= tm.hard {
+		return true
+	}
+
+	// Only move, there is nothing to think about
+	if len(root.Children) == 1 {
+		return true
+	}
+
+	// Find the most visited children
+	var best *NodeBase[T]
+	first, second := int32(0), int32(0)
+	for i := range root.Children {
+		child := &root.Children[i]
+		visits := child.RealVisits()
+		if visits > first {
+			best, first, second = child, visits, first
+		} else if visits > second {
+			second = visits
+		}
+	}
+
+	// The best move changes a lot at the beginning, count only the later changes
+	if best != tm.lastBest {
+		if tm.lastBest != nil && elapsed >= tm.soft/4 {
+			tm.changes++
+		}
+		tm.lastBest = best
+	}
+
+	// Unstable best move, each change extends the search
+	deadline := float64(tm.soft) * (1 + 0.25*float64(min(tm.changes, 4)))
+
+	// The lead is small, use more time
+	if first > 0 && float64(first-second)/float64(first) < 0.1 {
+		deadline *= 1.5
+	}
+	deadline = math.Min(deadline, float64(tm.hard))
+
+	if float64(elapsed) >= deadline {
+		return true
+	}
+
+	// Second best can't catch up with the leader, in the remaining time
+	if cps > 0 && elapsed >= tm.soft/10 {
+		remaining := float64(cps) * (deadline - float64(elapsed)) / 1000
+		if float64(first-second) > remaining {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package mcts
+
+import "testing"
+
+func TestTimeManagerAllocation(t *testing.T) {
+	cfg := DefaultSearchConfig()
+	tests := []struct {
+		name      string
+		timeLeft  int
+		increment int
+		movesToGo int
+	}{
+		{"sudden death", 60000, 0, 0},
+		{"increment", 60000, 1000, 0},
+		{"moves to go", 10000, 0, 2},
+		{"low time", 50, 0, 0},
+		{"low time with increment", 100, 2000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := DefaultLimits().SetClock(1, tt.timeLeft, tt.increment).SetMovesToGo(tt.movesToGo).SetSide(1)
+			if !limits.UseClock() || limits.Infinite {
+				t.Fatal("Limits should use the clock")
+			}
+
+			tm := NewTimeManager[int](limits, &cfg)
+			available := uint32(max(1, tt.timeLeft-cfg.MoveOverhead))
+			if tm.Soft() == 0 || tm.Soft() > tm.Hard() || tm.Hard() > available {
+				t.Errorf("Invalid deadlines soft=%d hard=%d, available=%d", tm.Soft(), tm.Hard(), available)
+			}
+		})
+	}
+
+	// More time with the increment, and with less moves to go
+	base := NewTimeManager[int](DefaultLimits().SetClock(0, 60000, 0), &cfg)
+	withInc := NewTimeManager[int](DefaultLimits().SetClock(0, 60000, 1000), &cfg)
+	fewMoves := NewTimeManager[int](DefaultLimits().SetClock(0, 60000, 0).SetMovesToGo(2), &cfg)
+	if withInc.Soft() <= base.Soft() || fewMoves.Soft() <= base.Soft() {
+		t.Errorf("Soft deadlines base=%d increment=%d moves-to-go=%d", base.Soft(), withInc.Soft(), fewMoves.Soft())
+	}
+
+	// Time management parameters are taken from the config
+	tuned := cfg
+	tuned.DefaultMovesToGo = 10
+	tuned.MoveOverhead = 1000
+	if tm := NewTimeManager[int](DefaultLimits().SetClock(0, 60000, 0), &tuned); tm.Soft() != 5900 {
+		t.Errorf("Soft deadline with the tuned config=%d, want=5900", tm.Soft())
+	}
+
+	// Other side's clock isn't used
+	if DefaultLimits().SetClock(0, 1000, 0).SetSide(1).UseClock() {
+		t.Error("Side without the clock shouldn't use it")
+	}
+}
+
+func TestTimeManagerStop(t *testing.T) {
+	cfg := DefaultSearchConfig()
+	newRoot := func(visits ...int32) *NodeBase[int] {
+		children := make([]int, len(visits))
+		root := expandTestNode(&NodeBase[int]{}, children...)
+		for i, v := range visits {
+			root.Children[i].SetVvl(v, 0)
+		}
+		return root
+	}
+
+	// soft=1000 hard=5000
+	limits := DefaultLimits().SetClock(0, 20*1000+cfg.MoveOverhead, 0)
+	tm := NewTimeManager[int](limits, &cfg)
+	if tm.Soft() != 1000 || tm.Hard() != 5000 {
+		t.Fatalf("soft=%d hard=%d, want=1000, 5000", tm.Soft(), tm.Hard())
+	}
+
+	tests := []struct {
+		name    string
+		visits  []int32
+		elapsed uint32
+		cps     uint32
+		stop    bool
+	}{
+		{"hard deadline", []int32{10, 100}, 5000, 0, true},
+		{"only move", []int32{1}, 1, 0, true},
+		{"before soft", []int32{50, 100}, 500, 0, false},
+		{"soft deadline", []int32{50, 100}, 1000, 0, true},
+		{"close lead extends", []int32{95, 100}, 1000, 0, false},
+		{"close lead extended deadline", []int32{95, 100}, 1500, 0, true},
+		{"can't be overtaken", []int32{100, 10000}, 200, 1000, true},
+		{"can be overtaken", []int32{100, 1000}, 200, 10000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTimeManager[int](limits, &cfg)
+			if stop := tm.ShouldStop(newRoot(tt.visits...), tt.elapsed, tt.cps); stop != tt.stop {
+				t.Errorf("stop=%v, want=%v", stop, tt.stop)
+			}
+		})
+	}
+
+	// Best move changes extend the search
+	tm = NewTimeManager[int](limits, &cfg)
+	root := newRoot(50, 100)
+	tm.ShouldStop(root, 300, 0)
+	root.Children[0].SetVvl(200, 0)
+	tm.ShouldStop(root, 400, 0)
+	if tm.ShouldStop(root, 1100, 0) {
+		t.Error("Search should be extended, after the best move changed")
+	}
+}
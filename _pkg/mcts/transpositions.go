@@ -0,0 +1,89 @@
+package mcts
+
+// DAG mode, the nodes reached by different move orders, with the same game state, share
+// their children (and so the statistics of the whole subtree below). The nodes themselves
+// aren't shared, each one keeps its own visits and outcomes, counting only the playouts
+// that went through it. Since a shared node has only one Parent, the search
+// backpropagates over the path that was actually taken.
+// The game must not repeat the states (the graph has to be acyclic)
+
+// Optional interface of the GameOperations, required by the DAG mode. The index
+// must be shared between the clones, and safe to use from multiple threads
+type TranspositionIndex[T MoveLike] interface {
+	// Find the node with the same game state as the current one (after traversing to 'node'),
+	// if there is none, store 'node' in the index and return nil
+	Transposition(node *NodeBase[T]) *NodeBase[T]
+}
+
+// Enable or disable the DAG mode, requires the GameOperations to implement the TranspositionIndex.
+// In this mode the arena (see SetArena) doesn't recycle the nodes, since they might be shared
+func (mcts *MCTS[T]) SetDAG(dag bool) {
+	mcts.dag = dag
+}
+
+func (mcts *MCTS[T]) DAG() bool {
+	return mcts.dag
+}
+
+// Number of the transpositions merged in the current search
+func (mcts *MCTS[T]) Transpositions() uint32 {
+	return mcts.transpositions.Load()
+}
+
+// Share the children of the already expanded transposition with the 'node', returns
+// false if there is no such node (or it isn't expanded yet), in that case 'node' should
+// be expanded normally. Must be called while expanding the node
+func (mcts *MCTS[T]) shareTransposition(ops GameOperations[T], node *NodeBase[T]) bool {
+	if !mcts.dag {
+		return false
+	}
+
+	index, ok := ops.(TranspositionIndex[T])
+	if !ok {
+		return false
+	}
+
+	other := index.Transposition(node)
+	if other == nil || other == node || !other.Expanded() {
+		return false
+	}
+
+	node.Children = other.Children
+	mcts.transpositions.Add(1)
+	return true
+}
+
+// Same as Backpropagate, but goes over the 'path' (from the root to the leaf)
+func (mcts *MCTS[T]) backpropagatePath(ops GameOperations[T], path []*NodeBase[T], result Result) {
+	leaf := path[len(path)-1]
+
+	// Terminal node's result is exact, back it up over the path
+	if mcts.solver && leaf.Terminal() {
+		if leaf.setProven(outcomeMask(1.0 - result)) {
+			for i := len(path) - 2; i >= 0; i-- {
+				mask := resolveProof(path[i])
+				if mask == 0 || !path[i].setProven(mask) {
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+
+		// Reverse virtual loss for non-root
+		if i > 0 {
+			node.AddVvl(1-mcts.search.VirtualLoss, -mcts.search.VirtualLoss)
+		} else {
+			node.AddVvl(1, 0)
+		}
+
+		result = 1.0 - result
+		node.AddOutcome(result)
+		if i > 0 {
+			ops.BackTraverse()
+		}
+		mcts.nodes.Add(1)
+	}
+}
@@ -0,0 +1,88 @@
+package mcts
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Game operations doing nothing, the transpositions are looked up in the 'index' by the node's signature
+type dagTestOps struct {
+	backTraversals int
+	index          map[int]*NodeBase[int]
+}
+
+func (ops *dagTestOps) ExpandNode(parent *NodeBase[int]) uint32 { return 0 }
+func (ops *dagTestOps) Traverse(int)                            {}
+func (ops *dagTestOps) BackTraverse()                           { ops.backTraversals++ }
+func (ops *dagTestOps) Rollout() Result                         { return 0.5 }
+func (ops *dagTestOps) Reset()                                  {}
+func (ops *dagTestOps) Clone() GameOperations[int]              { return ops }
+
+func (ops *dagTestOps) Transposition(node *NodeBase[int]) *NodeBase[int] {
+	if other, ok := ops.index[node.NodeSignature%10]; ok {
+		return other
+	}
+	ops.index[node.NodeSignature%10] = node
+	return nil
+}
+
+func TestDAGSharedChildren(t *testing.T) {
+	// root -> {1, 11}, both are the same position, 1 -> {3}
+	ops := &dagTestOps{index: make(map[int]*NodeBase[int])}
+	tree := &MCTS[int]{Root: expandTestNode(&NodeBase[int]{}, 1, 11), dag: true}
+	first, second := &tree.Root.Children[0], &tree.Root.Children[1]
+
+	if tree.shareTransposition(ops, first) {
+		t.Fatal("First node shouldn't have a transposition")
+	}
+	expandTestNode(first, 3)
+
+	if !tree.shareTransposition(ops, second) || &second.Children[0] != &first.Children[0] {
+		t.Fatal("Second node should share the children of the first one")
+	}
+	if tree.Transpositions() != 1 {
+		t.Errorf("Transpositions=%d, want=1", tree.Transpositions())
+	}
+	second.CanExpand()
+	second.FinishExpanding()
+
+	// Backpropagate over the second node, though the leaf's parent is the first one
+	tree.solver = true
+	leaf := &second.Children[0]
+	leaf.SetFlag(TerminalMask)
+	tree.backpropagatePath(ops, []*NodeBase[int]{tree.Root, second, leaf}, 0.0)
+
+	if ops.backTraversals != 2 {
+		t.Errorf("Back traversals=%d, want=2 (one per move on the path)", ops.backTraversals)
+	}
+	if tree.Root.Visits() != 1 || second.Visits() != 1 || leaf.Visits() != 1 || first.Visits() != 0 {
+		t.Errorf("Visits root=%d second=%d leaf=%d first=%d, want=1, 1, 1, 0",
+			tree.Root.Visits(), second.Visits(), leaf.Visits(), first.Visits())
+	}
+
+	// Leaf is a win for the player moving into it, so the second node is lost
+	if !leaf.ProvenWin() || !second.ProvenLoss() || tree.Root.Proven() {
+		t.Errorf("Invalid proofs: leaf=%v second=%v root=%v", leaf.ProvenWin(), second.ProvenLoss(), tree.Root.Proven())
+	}
+	if leaf.Outcomes() != 1.0 || second.Outcomes() != 0.0 || tree.Root.Outcomes() != 1.0 {
+		t.Errorf("Outcomes leaf=%f second=%f root=%f", leaf.Outcomes(), second.Outcomes(), tree.Root.Outcomes())
+	}
+}
+
+func TestDAGCountAndSave(t *testing.T) {
+	// root -> {1, 11}, both share the children {3, 4}, 3 -> {5}
+	ops := &dagTestOps{index: make(map[int]*NodeBase[int])}
+	tree := &MCTS[int]{Root: expandTestNode(&NodeBase[int]{}, 1, 11), dag: true}
+	first, second := &tree.Root.Children[0], &tree.Root.Children[1]
+	tree.shareTransposition(ops, first)
+	expandTestNode(first, 3, 4)
+	expandTestNode(&first.Children[0], 5)
+	tree.shareTransposition(ops, second)
+
+	if count := tree.Count(); count != 6 {
+		t.Errorf("Count=%d, want=6 (shared children counted once)", count)
+	}
+	if _, err := tree.WriteTree(&bytes.Buffer{}, ""); err == nil {
+		t.Error("Saving the tree in the DAG mode should fail")
+	}
+}
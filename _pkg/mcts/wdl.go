@@ -0,0 +1,66 @@
+package mcts
+
+// Win/draw/loss statistics of the nodes, so a drawish position can be told apart
+// from a sharp one with the same average outcome
+
+// Fixed-point precision of the outcome counters (and the AMAF ones, see AddAmafOutcome)
+const outcomeScale = 1e3
+
+// Win/draw/loss probabilities, from the perspective of the player who made the move
+type WDL struct {
+	Win  float64 `json:"win"`
+	Draw float64 `json:"draw"`
+	Loss float64 `json:"loss"`
+}
+
+// Split the result into the win, draw and loss parts (summing up to 1), keeping
+// win + draw/2 equal to the result. Results 0, 0.5 and 1 are a pure loss, draw and win,
+// the ones in between (for example the evaluations) are mixed with a draw
+func SplitOutcome(result Result) (win, draw, loss Result) {
+	result = min(max(result, 0), 1)
+	win = max(0, 2*result-1)
+	loss = max(0, 1-2*result)
+	return win, 1 - win - loss, loss
+}
+
+// Get the win/draw/loss probabilities of the node, zero if it has no outcomes yet
+func (node *NodeBase[T]) WDL() WDL {
+	w, d, l := node.wins.Load(), node.draws.Load(), node.losses.Load()
+	total := float64(w + d + l)
+	if total == 0 {
+		return WDL{}
+	}
+	return WDL{Win: float64(w) / total, Draw: float64(d) / total, Loss: float64(l) / total}
+}
+
+// Value of the node, with the draws worth 'drawValue' instead of a half of a win
+func (node *NodeBase[T]) ValueWithDraw(drawValue float64) float64 {
+	wdl := node.WDL()
+	return wdl.Win + drawValue*wdl.Draw
+}
+
+func (stats *NodeStats) addWDL(wins, draws, losses uint64) {
+	if wins > 0 {
+		stats.wins.Add(wins)
+	}
+	if draws > 0 {
+		stats.draws.Add(draws)
+	}
+	if losses > 0 {
+		stats.losses.Add(losses)
+	}
+}
+
+// Add the other node's counters to these ones
+func (stats *NodeStats) mergeWDL(other *NodeStats) {
+	stats.addWDL(other.wins.Load(), other.draws.Load(), other.losses.Load())
+	stats.squares.Add(other.squares.Load())
+}
+
+// Replace the counters with the other node's ones
+func (stats *NodeStats) copyWDL(other *NodeStats) {
+	stats.wins.Store(other.wins.Load())
+	stats.draws.Store(other.draws.Load())
+	stats.losses.Store(other.losses.Load())
+	stats.squares.Store(other.squares.Load())
+}
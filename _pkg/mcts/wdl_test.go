@@ -0,0 +1,100 @@
+package mcts
+
+import "testing"
+
+func TestSplitOutcome(t *testing.T) {
+	tests := []struct {
+		result          Result
+		win, draw, loss Result
+	}{
+		{0, 0, 0, 1},
+		{0.5, 0, 1, 0},
+		{1, 1, 0, 0},
+		{0.75, 0.5, 0.5, 0},
+		{0.25, 0, 0.5, 0.5},
+		{1.5, 1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		win, draw, loss := SplitOutcome(tt.result)
+		if win != tt.win || draw != tt.draw || loss != tt.loss {
+			t.Errorf("SplitOutcome(%v)=%v, %v, %v, want=%v, %v, %v", tt.result, win, draw, loss, tt.win, tt.draw, tt.loss)
+		}
+	}
+}
+
+func TestNodeWDL(t *testing.T) {
+	// Same average outcome, but one of them is drawish
+	drawish, sharp := &NodeBase[int]{}, &NodeBase[int]{}
+	for i := range 10 {
+		drawish.AddOutcome(0.5)
+		sharp.AddOutcome(Result(i % 2))
+	}
+	drawish.SetVvl(10, 0)
+	sharp.SetVvl(10, 0)
+
+	if drawish.AvgOutcome() != sharp.AvgOutcome() {
+		t.Errorf("Average outcomes differ %v, %v", drawish.AvgOutcome(), sharp.AvgOutcome())
+	}
+	if wdl := drawish.WDL(); wdl != (WDL{Draw: 1}) {
+		t.Errorf("Drawish WDL=%+v", wdl)
+	}
+	if wdl := sharp.WDL(); wdl != (WDL{Win: 0.5, Loss: 0.5}) {
+		t.Errorf("Sharp WDL=%+v", wdl)
+	}
+	if v := sharp.ValueWithDraw(0); v != 0.5 {
+		t.Errorf("Sharp value=%v, want=0.5", v)
+	}
+	if (&NodeBase[int]{}).WDL() != (WDL{}) {
+		t.Error("Node without outcomes should have zero WDL")
+	}
+}
+
+func TestBestChildContempt(t *testing.T) {
+	// Drawish child with the most visits, and a sharp (but better) one
+	newTree := func() *MCTS[int] {
+		tree := &MCTS[int]{Root: expandTestNode(&NodeBase[int]{}, 1, 2), Limiter: NewLimiter(0)}
+		drawish, sharp := &tree.Root.Children[0], &tree.Root.Children[1]
+		drawish.SetVvl(100, 0)
+		for range 100 {
+			drawish.AddOutcome(0.5)
+		}
+		sharp.SetVvl(80, 0)
+		for i := range 80 {
+			sharp.AddOutcome(Result(min(1, i%5)))
+		}
+		tree.Root.SetVvl(180, 0)
+		return tree
+	}
+
+	tests := []struct {
+		name     string
+		contempt float64
+		policy   BestChildPolicy
+		want     int
+	}{
+		{"no contempt", 0, BestChildMostVisits, 1},
+		{"avoid draws", 0.2, BestChildMostVisits, 2},
+		{"prefer draws", -0.4, BestChildMostVisits, 1},
+		{"lead bigger than contempt", -0.2, BestChildMostVisits, 2},
+		{"win rate prefers draws", -0.4, BestChildWinRate, 1},
+		{"win rate no contempt", 0, BestChildWinRate, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newTree()
+			tree.SetContempt(tt.contempt)
+			if best := tree.BestChild(tree.Root, tt.policy); best == nil || best.NodeSignature != tt.want {
+				t.Errorf("BestChild=%v, want=%d", best, tt.want)
+			}
+		})
+	}
+
+	// The opponent values the draws the other way around
+	tree := newTree()
+	tree.SetContempt(0.2)
+	if v := tree.drawValue(&tree.Root.Children[0]); v != 0.7 {
+		t.Errorf("Opponent's draw value=%v, want=0.7", v)
+	}
+}
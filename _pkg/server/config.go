@@ -33,6 +33,7 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `json:"read_timeout"`
 	WriteTimeout    time.Duration `json:"write_timeout"`
 	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	Debug           bool          `json:"debug"` // enables the debug endpoints, like /api/debug/tree
 }
 
 // Pool config
@@ -50,6 +51,10 @@ type EngineConfig struct {
 	MaxSizeMb     int         `json:"mbsize"` // maximum size of the tree in mb
 	MaxMultiPv    int         `json:"multipv"`
 	Threads       int         `json:"threads"` // number of threads to use by default
+	// Period of the analysis updates, sent to the SSE clients (besides the best move changes)
+	StreamInterval time.Duration `json:"stream_interval"`
+	// Default search parameters, each request may override them
+	Search mcts.SearchConfig `json:"search"`
 }
 
 type RateLimitConfig struct {
@@ -75,6 +80,7 @@ func LoadConfig() {
 			ReadTimeout:     utils.GetEnvDuration("READ_TIMEOUT", 30*time.Second),
 			WriteTimeout:    utils.GetEnvDuration("WRITE_TIMEOUT", 30*time.Second),
 			ShutdownTimeout: utils.GetEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+			Debug:           utils.GetEnvBool("DEBUG", false),
 		},
 		Pool: PoolConfig{
 			DefaultWorkers:   utils.GetEnvInt("WORKERS", 4),
@@ -82,12 +88,14 @@ func LoadConfig() {
 			JobTimeout:       utils.GetEnvDuration("JOB_TIMEOUT", 5*time.Second),
 		},
 		Engine: EngineConfig{
-			DefaultLimits: *mcts.DefaultLimits(),
-			MaxDepth:      utils.GetEnvInt("MAX_DEPTH", 14),
-			MaxMovetime:   utils.GetEnvInt("MAX_MOVETIME", 9000),
-			MaxSizeMb:     utils.GetEnvInt("MAX_TREE_SIZE_MB", 16),
-			Threads:       utils.GetEnvInt("N_SEARCH_THREADS", 4),
-			MaxMultiPv:    utils.GetEnvInt("MAX_MULTI_PV", 3),
+			DefaultLimits:  *mcts.DefaultLimits(),
+			MaxDepth:       utils.GetEnvInt("MAX_DEPTH", 14),
+			MaxMovetime:    utils.GetEnvInt("MAX_MOVETIME", 9000),
+			MaxSizeMb:      utils.GetEnvInt("MAX_TREE_SIZE_MB", 16),
+			Threads:        utils.GetEnvInt("N_SEARCH_THREADS", 4),
+			MaxMultiPv:     utils.GetEnvInt("MAX_MULTI_PV", 3),
+			StreamInterval: utils.GetEnvDuration("STREAM_INTERVAL", 250*time.Millisecond),
+			Search:         mcts.DefaultSearchConfig(),
 		},
 		Rate: RateLimitConfig{
 			RequestsPerSecond: rate.Limit(utils.GetEnvInt("RATE_LIMIT_RPS", 5)),
@@ -99,4 +107,7 @@ func LoadConfig() {
 		SetMovetime(1000).
 		SetMbSize(DefaultConfig.Engine.MaxSizeMb).
 		SetThreads(DefaultConfig.Engine.Threads)
+
+	// Selection policy by its name, see mcts.SelectionPolicyNames
+	DefaultConfig.Engine.Search.Policy = utils.GetEnv("SELECTION_POLICY", "")
 }
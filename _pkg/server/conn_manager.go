@@ -91,3 +91,27 @@ func (cm *ConnManager) Publish(userId, connId string, event AnalysisEvent) {
 		}
 	}
 }
+
+// Publish the last event of the analysis, it's never dropped: if the channel is full,
+// the oldest events are dropped to make room for it
+func (cm *ConnManager) PublishFinal(userId, connId string, event AnalysisEvent) {
+	// Hold the lock, so the channel can't be closed by Unsubscribe in the meantime
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	c := cm.clients[userId][connId]
+	if c == nil {
+		return
+	}
+	for {
+		select {
+		case c.Events <- event:
+			return
+		default:
+			select {
+			case <-c.Events:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	uttt "uttt/_pkg/engine"
+	"uttt/_pkg/mcts"
+)
+
+// Debug-only endpoint (enabled with DEBUG=true), runs a short search on the given position
+// and returns the search tree, query parameters:
+// position (encoded the same way as in rt-analysis), movetime (ms), depth, minvisits, format=json|dot
+func TreeDebugHandler(workerPool *WorkerPool, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		atoi := func(s string, def int) int {
+			if v, err := strconv.Atoi(s); err == nil {
+				return v
+			}
+			return def
+		}
+
+		notation := uttt.StartingPosition
+		if position := q.Get("position"); position != "" {
+			notation = strings.ReplaceAll(strings.ReplaceAll(position, "n", "/"), "_", " ")
+		}
+
+		format := q.Get("format")
+		if format != "" && format != "json" && format != "dot" {
+			http.Error(w, "Invalid format, expected json or dot", http.StatusBadRequest)
+			return
+		}
+
+		opts := mcts.ExportOptions{
+			MaxDepth:  min(max(atoi(q.Get("depth"), 2), 0), DefaultConfig.Engine.MaxDepth),
+			MinVisits: int32(max(atoi(q.Get("minvisits"), 0), 0)),
+		}
+
+		// Run the search on the worker pool, like any other analysis, and export
+		// the tree before the worker takes the next job
+		var buffer bytes.Buffer
+		var exportErr error
+		req := &AnalysisRequest{
+			BaseAnalysisRequest: BaseAnalysisRequest{
+				Position: notation,
+				Movetime: min(max(atoi(q.Get("movetime"), 200), 1), DefaultConfig.Engine.MaxMovetime),
+				Threads:  1,
+				MultiPv:  1,
+				Config:   DefaultConfig.Engine.Search,
+			},
+			JobId:    RandID(16),
+			Ctx:      r.Context(),
+			Response: make(chan AnalysisResponse, 1),
+			Listener: mcts.StatsListener[uttt.PosType]{},
+			OnResult: func(engine *uttt.Engine) {
+				if format == "dot" {
+					exportErr = mcts.WriteDot(&buffer, engine.Mcts().Root, opts)
+				} else {
+					exportErr = mcts.WriteJSON(&buffer, engine.Mcts().Root, opts)
+				}
+			},
+		}
+
+		if !workerPool.Submit(req) {
+			http.Error(w, "Server is too busy", http.StatusServiceUnavailable)
+			return
+		}
+
+		select {
+		case resp := <-req.Response:
+			if resp.Error != "" {
+				http.Error(w, resp.Error, http.StatusBadRequest)
+				return
+			}
+		case <-time.After(DefaultConfig.Pool.JobTimeout):
+			http.Error(w, "Analysis timeout", http.StatusRequestTimeout)
+			return
+		}
+
+		if exportErr != nil {
+			logger.Error("Failed to export the tree", "error", exportErr)
+			http.Error(w, "Failed to export the tree", http.StatusInternalServerError)
+			return
+		}
+
+		if format == "dot" {
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		if _, err := buffer.WriteTo(w); err != nil {
+			logger.Error("Failed to send the tree", "error", err)
+		}
+	}
+}
@@ -4,13 +4,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	uttt "uttt/_pkg/engine"
 )
 
+// Engine config, with the description of the engine's options
+type limitsResponse struct {
+	EngineConfig
+	Options []uttt.Option `json:"options"`
+}
+
+// Engine's options, limited to the server's maximums
+func engineOptions() []uttt.Option {
+	options := uttt.NewEngine().Options().List()
+	for i := range options {
+		option := &options[i]
+		switch option.Name {
+		case "Threads":
+			option.Max = float64(DefaultConfig.Engine.Threads)
+			option.Default = fmt.Sprint(DefaultConfig.Engine.Threads)
+		case "Hash":
+			option.Max = float64(DefaultConfig.Engine.MaxSizeMb)
+			option.Default = fmt.Sprint(DefaultConfig.Engine.MaxSizeMb)
+		case "MultiPV":
+			option.Max = float64(DefaultConfig.Engine.MaxMultiPv)
+		}
+	}
+	return options
+}
+
 func LimitsHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Simply return current engine config
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(DefaultConfig.Engine); err != nil {
+		response := limitsResponse{EngineConfig: DefaultConfig.Engine, Options: engineOptions()}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
 			fmt.Println(err)
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 	uttt "uttt/_pkg/engine"
 	"uttt/_pkg/mcts"
@@ -98,6 +99,7 @@ func AnalysisSSESubmit(workerPool *WorkerPool, cm *ConnManager, logger *slog.Log
 
 		// Get the connection ID from the body
 		var sseReq SseAnalysisRequest
+		sseReq.Config = DefaultConfig.Engine.Search
 		if err := json.NewDecoder(r.Body).Decode(&sseReq); err != nil {
 			http.Error(w, "Invalid request", http.StatusBadRequest)
 			return
@@ -117,31 +119,46 @@ func AnalysisSSESubmit(workerPool *WorkerPool, cm *ConnManager, logger *slog.Log
 		}
 
 		turn, _ := uttt.ReadTurn(sseReq.Position)
-		sseReq.Listener.
-			OnDepth(func(lts mcts.ListenerTreeStats[uttt.PosType]) {
+		format, _ := sseReq.MoveFormat()
+		var lastPublish atomic.Int64 // time of the last progress event, in nanoseconds
+		publish := func(final bool) mcts.ListenerFunc[uttt.PosType] {
+			return func(lts mcts.ListenerTreeStats[uttt.PosType]) {
 				result := uttt.ToSearchResult(lts, turn)
-
-				cm.Publish(userId, sseReq.ConnId, AnalysisEvent{
+				event := AnalysisEvent{
 					AnalysisResponse: AnalysisResponse{
-						Lines: ToAnalysisLine(result.Lines, result.Turn),
+						Lines: ToAnalysisLine(result.Lines, result.Turn, format),
 						Depth: result.Depth,
 						Cps:   result.Cps,
-						Final: false,
+						Final: final,
 					},
-				})
-			}).
-			OnStop(func(lts mcts.ListenerTreeStats[uttt.PosType]) {
-				result := uttt.ToSearchResult(lts, turn)
+				}
 
-				cm.Publish(userId, sseReq.ConnId, AnalysisEvent{
-					AnalysisResponse: AnalysisResponse{
-						Lines: ToAnalysisLine(result.Lines, result.Turn),
-						Depth: result.Depth,
-						Cps:   result.Cps,
-						Final: true,
-					},
-				})
-			})
+				// The final event can't be dropped, the client waits for it
+				if final {
+					cm.PublishFinal(userId, sseReq.ConnId, event)
+					return
+				}
+				lastPublish.Store(time.Now().UnixNano())
+				cm.Publish(userId, sseReq.ConnId, event)
+			}
+		}
+
+		// Best move changes often early in the search, so it's published at most twice per
+		// interval, otherwise it would fill up the client's channel
+		minGap := int64(DefaultConfig.Engine.StreamInterval / 2)
+		progress := publish(false)
+		onBestMoveChange := func(lts mcts.ListenerTreeStats[uttt.PosType]) {
+			now, last := time.Now().UnixNano(), lastPublish.Load()
+			if now-last >= minGap && lastPublish.CompareAndSwap(last, now) {
+				progress(lts)
+			}
+		}
+
+		// Stream the analysis at a fixed cadence, and as soon as the best move changes
+		sseReq.Listener.
+			OnInterval(DefaultConfig.Engine.StreamInterval, progress).
+			OnBestMoveChange(onBestMoveChange).
+			OnStop(publish(true))
 
 		// Make sure the 'Response' channel is not used
 		sseReq.PublishLastWithStop = true
@@ -16,19 +16,18 @@ import (
 type AnalysisLine struct {
 	Eval    string   `json:"eval"`
 	AbsEval string   `json:"abseval,omitempty"`
+	WDL     mcts.WDL `json:"wdl"` // from the perspective of the side to move
 	Pv      []string `json:"pv"`
 }
 
-// Simply convert pv moves to pv strings, and convert eval to a string
-func ToAnalysisLine(engineLines []uttt.EngineLine, turn uttt.TurnType) []AnalysisLine {
+// Simply convert pv moves to pv strings (in given format), and convert eval to a string
+func ToAnalysisLine(engineLines []uttt.EngineLine, turn uttt.TurnType, format uttt.MoveFormat) []AnalysisLine {
 	lines := make([]AnalysisLine, len(engineLines))
 	for i := range len(engineLines) {
 		lines[i].Eval = engineLines[i].StringValue(turn, false)
 		lines[i].AbsEval = engineLines[i].StringValue(turn, true)
-		lines[i].Pv = make([]string, len(engineLines[i].Pv))
-		for j := range len(engineLines[i].Pv) {
-			lines[i].Pv[j] = engineLines[i].Pv[j].String()
-		}
+		lines[i].WDL = engineLines[i].WDL
+		lines[i].Pv = uttt.FormatMoves(engineLines[i].Pv, format)
 	}
 	return lines
 }
@@ -48,6 +47,11 @@ type BaseAnalysisRequest struct {
 	Threads  int    `json:"threads,omitempty"`
 	SizeMb   int    `json:"sizemb,omitempty"`
 	MultiPv  int    `json:"multipv,omitempty"`
+	// Format of the pv moves: standard (default), index, rowcol or codingame, same as
+	// the engine's MoveFormat option
+	Format string `json:"moveformat,omitempty"`
+	// Search parameters, the missing ones are taken from the server's config
+	Config mcts.SearchConfig `json:"config"`
 }
 
 type AnalysisRequest struct {
@@ -58,6 +62,8 @@ type AnalysisRequest struct {
 	Ctx                 context.Context                  `json:"-"`
 	Response            chan AnalysisResponse            `json:"-"`
 	Listener            mcts.StatsListener[uttt.PosType] `json:"-"`
+	// Optional, called by the worker after the search, before sending the final response
+	OnResult func(engine *uttt.Engine) `json:"-"`
 }
 
 func NewAnalysisRequest(r *http.Request, useQuery bool) (*AnalysisRequest, error) {
@@ -86,7 +92,18 @@ func NewAnalysisRequest(r *http.Request, useQuery bool) (*AnalysisRequest, error
 		req.Threads = atoi(q.Get("threads"), 0)
 		req.SizeMb = atoi(q.Get("sizemb"), 0)
 		req.MultiPv = atoi(q.Get("multipv"), 0)
+		req.Format = q.Get("moveformat")
+
+		req.Config = DefaultConfig.Engine.Search
+		for _, name := range mcts.SearchConfigNames {
+			if value := q.Get(name); value != "" {
+				if err := req.Config.Set(name, value); err != nil {
+					return nil, err
+				}
+			}
+		}
 	} else {
+		req.Config = DefaultConfig.Engine.Search
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return nil, fmt.Errorf("failed to decode request body: %w", err)
 		}
@@ -107,6 +124,13 @@ func (r *AnalysisRequest) Validate() error {
 		return nil
 	}
 
+	if err := r.Config.Validate(); err != nil {
+		return err
+	}
+	if _, err := r.MoveFormat(); err != nil {
+		return err
+	}
+
 	// Check if that's a 'default' request
 	if r.Position != "" && r.Movetime == 0 && r.Depth == 0 && r.Threads == 0 && r.SizeMb == 0 && r.MultiPv == 0 {
 		// Set default values
@@ -136,6 +160,18 @@ func (r *AnalysisRequest) Validate() error {
 	return nil
 }
 
+// Format of the pv moves, set by the 'moveformat' parameter
+func (r *BaseAnalysisRequest) MoveFormat() (uttt.MoveFormat, error) {
+	if r.Format == "" {
+		return uttt.FormatStandard, nil
+	}
+	format, err := uttt.ParseMoveFormat(r.Format)
+	if err == nil && format == uttt.FormatAuto {
+		err = fmt.Errorf("Invalid moveformat value: %s", r.Format)
+	}
+	return format, err
+}
+
 func (r AnalysisRequest) String() string {
 	builder := strings.Builder{}
 	if err := json.NewEncoder(&builder).Encode(r); err != nil {
@@ -231,17 +267,19 @@ func (wp *WorkerPool) worker(id int) {
 	}
 }
 
-// A simple timeout funciton, call engine.Stop() after MaxMovetime elapses, else does nothing
-func searchTimeout(ctx context.Context, req *AnalysisRequest, engine *uttt.Engine) {
-	select {
-	// Background context canceled
-	case <-ctx.Done():
-		engine.Stop()
-		// Request context canceled
-	case <-req.Ctx.Done():
-		engine.Stop()
-	case <-time.After(time.Duration(DefaultConfig.Engine.MaxMovetime) * time.Millisecond):
-		engine.Stop()
+// Get the search's context, cancelled when the request is done, the pool is shut down,
+// or after MaxMovetime elapses
+func (wp *WorkerPool) searchContext(req *AnalysisRequest) (context.Context, context.CancelFunc) {
+	parent := req.Ctx
+	if parent == nil {
+		parent = wp.ctx
+	}
+
+	ctx, cancel := context.WithTimeout(parent, time.Duration(DefaultConfig.Engine.MaxMovetime)*time.Millisecond)
+	stop := context.AfterFunc(wp.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
 	}
 }
 
@@ -293,17 +331,28 @@ func (wp *WorkerPool) handleSearch(req *AnalysisRequest, engine *uttt.Engine) er
 		return fmt.Errorf("Invalid position notation: %s", notation)
 	}
 
+	// Search parameters are set per engine, so they don't affect the other workers
+	if err := engine.SetSearchConfig(req.Config); err != nil {
+		req.Response <- AnalysisResponse{
+			Error: err.Error(),
+		}
+		return err
+	}
+
 	// Get the limits and attach new listener
 	limits := getAnalysisLimits(req)
 	engine.Mcts().ResetListener()
 	*engine.Mcts().StatsListener() = req.Listener
 
-	// Use here a timeout
-	go searchTimeout(wp.ctx, req, engine)
+	// Search on the request's context, and return the result
+	ctx, cancel := wp.searchContext(req)
+	defer cancel()
 
-	// Search, and return the result
 	engine.SetLimits(limits)
-	result := engine.Think()
+	result := engine.ThinkContext(ctx)
+	if req.OnResult != nil {
+		req.OnResult(engine)
+	}
 
 	// Set the response object
 	if req.PublishLastWithStop {
@@ -312,8 +361,9 @@ func (wp *WorkerPool) handleSearch(req *AnalysisRequest, engine *uttt.Engine) er
 		return nil
 	}
 
+	format, _ := req.MoveFormat()
 	req.Response <- AnalysisResponse{
-		Lines: ToAnalysisLine(result.Lines, engine.Position().Turn()),
+		Lines: ToAnalysisLine(result.Lines, engine.Position().Turn(), format),
 		Depth: result.Depth,
 		Cps:   result.Cps,
 		Final: true,
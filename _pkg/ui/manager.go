@@ -70,7 +70,7 @@ func (m *Manager) handleCommand(line string) error {
 		}
 		return m.handleMake(tokens[1:])
 	case "undo":
-		m.e.Position().UndoMove()
+		m.e.UndoMove()
 		m.updateBoard()
 		m.board.RenderBoard()
 	case "getpos":
@@ -79,7 +79,9 @@ func (m *Manager) handleCommand(line string) error {
 		m.e.SetLimits(m.limits)
 		result, _ := m.e.Think().MainLine()
 		if result.Bestmove != engine.PosIllegal && m.e.Position().IsLegal(result.Bestmove) {
-			m.e.Position().MakeMove(result.Bestmove)
+			if err := m.e.MakeMove(result.Bestmove); err != nil {
+				return err
+			}
 			defer PrintOK("[Engine]", fmt.Sprintf("Engine played %v", result.Bestmove))
 		} else {
 			defer PrintError("[Engine]", "No valid best move found.")
@@ -101,13 +103,17 @@ func (m *Manager) handleCommand(line string) error {
 	return nil
 }
 
-func (m *Manager) makeMove(move engine.PosType) {
-	m.e.Position().MakeMove(move)
+// makeMove plays the move through the engine, so the search tree of this move is kept
+func (m *Manager) makeMove(move engine.PosType) error {
+	if err := m.e.MakeMove(move); err != nil {
+		return err
+	}
 	if m.e.Position().IsTerminated() {
 		defer PrintError("[Manager]", "Position is terminated")
 	}
 	m.updateBoard()
 	m.board.RenderBoard()
+	return nil
 }
 
 // handleMake attempts to play user-specified moves, then waits briefly for engine response.
@@ -120,7 +126,9 @@ func (m *Manager) handleMake(moves []string) error {
 		if !m.e.Position().IsLegal(mv) {
 			return fmt.Errorf("Illegal move: %s", mvtxt)
 		}
-		m.makeMove(mv)
+		if err := m.makeMove(mv); err != nil {
+			return err
+		}
 	}
 	fmt.Print(CLEAR_SCREEN_FROM_CURSOR)
 
@@ -128,7 +136,9 @@ func (m *Manager) handleMake(moves []string) error {
 	m.e.SetLimits(m.limits)
 	res, _ := m.e.Think().MainLine()
 	if res.Bestmove != engine.PosIllegal && m.e.Position().IsLegal(res.Bestmove) {
-		m.makeMove(res.Bestmove)
+		if err := m.makeMove(res.Bestmove); err != nil {
+			return err
+		}
 	}
 	fmt.Print(CLEAR_SCREEN_FROM_CURSOR)
 
@@ -11,7 +11,7 @@ import (
 // Else returns defaultValue
 func GetEnvT[T any](key string, defaultValue T, parser func(string) (T, error)) T {
 	if value := os.Getenv(key); value != "" {
-		if val, err := parser(key); err == nil {
+		if val, err := parser(value); err == nil {
 			return val
 		}
 	}
@@ -19,7 +19,7 @@ func GetEnvT[T any](key string, defaultValue T, parser func(string) (T, error))
 }
 
 func GetEnv(key, defaultValue string) string {
-	return GetEnvT(key, defaultValue, func(s string) (string, error) { return defaultValue, nil })
+	return GetEnvT(key, defaultValue, func(s string) (string, error) { return s, nil })
 }
 
 func GetEnvInt(key string, defaultValue int) int {
@@ -29,3 +29,7 @@ func GetEnvInt(key string, defaultValue int) int {
 func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return GetEnvT(key, defaultValue, time.ParseDuration)
 }
+
+func GetEnvBool(key string, defaultValue bool) bool {
+	return GetEnvT(key, defaultValue, strconv.ParseBool)
+}
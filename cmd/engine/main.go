@@ -2,12 +2,23 @@ package main
 
 import (
 	"fmt"
+	"os"
 	uttt "uttt/_pkg/engine"
 	"uttt/_pkg/mcts"
 )
 
+// Run the engine protocol on stdin/stdout (see docs/protocol.md),
+// or the fixed benchmark search with 'bench' argument
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		bench()
+		return
+	}
 
+	uttt.NewCli().Start()
+}
+
+func bench() {
 	engine := uttt.NewEngine()
 	engine.SetLimits(mcts.DefaultLimits().SetThreads(4).SetDepth(13).SetMbSize(16).SetMultiPv(3))
 	engine.SetNotation("8o/9/x8/9/6x2/9/2o6/9/x8 o 0")
@@ -63,7 +74,4 @@ func main() {
 	// 		fmt.Println()
 	// 	}
 	// }
-
-	// cli := uttt.NewCli()
-	// cli.Start()
 }
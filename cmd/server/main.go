@@ -76,6 +76,11 @@ func main() {
 	router.HandleFunc("/api/healthz", server.HealthzHandler())         // either 204 or 503 response
 	router.HandleFunc("/api/metrics", server.MetricsHandler())         // memory usage, pool usage and other stats
 
+	// Search tree dump, for debugging the engine
+	if server.DefaultConfig.Server.Debug {
+		router.HandleFunc("/api/debug/tree", server.TreeDebugHandler(workerPool, logger)).Methods("GET")
+	}
+
 	srv := &http.Server{
 		Addr:         ":" + server.DefaultConfig.Server.Port,
 		Handler:      router,
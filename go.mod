@@ -6,12 +6,10 @@ toolchain go1.24.3
 
 require (
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/sessions v1.4.0
 	github.com/gorilla/websocket v1.5.3
 	github.com/joho/godotenv v1.5.1
 	golang.org/x/time v0.12.0
 )
 
-require (
-	github.com/gorilla/securecookie v1.1.2 // indirect
-	github.com/gorilla/sessions v1.4.0 // indirect
-)
+require github.com/gorilla/securecookie v1.1.2 // indirect